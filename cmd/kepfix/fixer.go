@@ -1,13 +1,13 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"flag"
 	"fmt"
-	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -25,7 +25,11 @@ const (
 
 func main() {
 	var dryRun bool
-	flag.BoolVar(&dryRun, "dry-run", false, "edit files in place")
+	var toc bool
+	flag.BoolVar(&dryRun, "dry-run", false, "report problems without editing files")
+	flag.StringVar(&backups.suffix, "backup", "", "copy each file to <file><suffix> before editing it")
+	flag.BoolVar(&backups.overwrite, "overwrite-backup", false, "replace backups left by an earlier run")
+	flag.BoolVar(&toc, "toc", false, "regenerate the table of contents between the toc markers")
 	flag.Parse()
 	for _, path := range flag.Args() {
 		if err := FixYAML(path, dryRun); err != nil {
@@ -36,6 +40,13 @@ func main() {
 			fmt.Printf("%q\n%+v", path, err)
 			os.Exit(1)
 		}
+		if !toc {
+			continue
+		}
+		if err := fixTableOfContents(path, dryRun); err != nil {
+			fmt.Printf("%q\n%+v", path, err)
+			os.Exit(1)
+		}
 	}
 }
 
@@ -102,6 +113,9 @@ func (m *metadata) Field(key string) (int, int) {
 			return start, end
 		}
 	}
+	if start >= 0 {
+		end = len(*m) - 1
+	}
 	return start, end
 }
 
@@ -121,22 +135,19 @@ func getCreatedTime(path string) (string, error) {
 }
 
 func getLastCommitTime(path string) (string, error) {
-	cmd := exec.Command("git", "log", "-1", `--format="%ad"`, "--", path)
-	cmd.Dir = kepRepo
-	t, err := cmd.Output()
-	if err != nil {
-		return "", errors.WithStack(err)
-	}
-	t = bytes.Trim(bytes.TrimSpace(t), `"`)
-	lastUpdate, err := time.Parse("Mon Jan 2 15:04:05 2006 -0700", string(t))
+	lastUpdate, err := keps.LastCommitTime(path)
 	if err != nil {
-		return "", errors.WithStack(err)
+		return "", err
 	}
 	return lastUpdate.Format("2006-01-02"), nil
 }
 
 func FixData(path string, dryRun bool) error {
-	_, head, meta, body, _ := openProposal(path)
+	_, frontMatter, err := openProposal(path)
+	if err != nil || frontMatter == nil {
+		return err
+	}
+	meta := frontMatter.Metadata
 	out := make(map[string]interface{})
 	if err := yaml.Unmarshal(meta, out); err != nil {
 		return errors.WithStack(err)
@@ -313,7 +324,11 @@ func FixData(path string, dryRun bool) error {
 			}
 		}
 	}
-	if err := writeFile(path, head, buf.Bytes(), body); err != nil {
+	if dryRun {
+		return nil
+	}
+	frontMatter.Metadata = buf.Bytes()
+	if err := writeFile(path, frontMatter); err != nil {
 		return errors.WithStack(err)
 	}
 
@@ -340,17 +355,17 @@ var keyFindRe = regexp.MustCompile(`\s*[a-z]+:`)
 var valStartsWithAmpersand = regexp.MustCompile(` (- )?"?@`)
 
 func fixMapInListContext(path string, dryRun bool) error {
-	proposal, head, meta, body, err := openProposal(path)
+	proposal, frontMatter, err := openProposal(path)
 	if err != nil {
 		return err
 	}
 	if proposal == nil {
 		return nil
 	}
-	if proposal.Error == nil {
+	if !proposal.HasErrors() {
 		return nil
 	}
-	lines := bytes.Split(meta, []byte("\n"))
+	lines := bytes.Split(frontMatter.Metadata, []byte("\n"))
 
 	// using an object in a list context
 	matches := errRe.FindAllStringSubmatch(proposal.Error.Error(), -1)
@@ -370,21 +385,22 @@ func fixMapInListContext(path string, dryRun bool) error {
 	if dryRun {
 		return nil
 	}
-	if err := writeFile(path, head, bytes.Join(lines, []byte("\n")), body); err != nil {
+	frontMatter.Metadata = bytes.Join(lines, []byte("\n"))
+	if err := writeFile(path, frontMatter); err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
 func fixBareAtSign(path string, dryRun bool) error {
-	proposal, head, meta, body, err := openProposal(path)
+	proposal, frontMatter, err := openProposal(path)
 	if err != nil {
 		return err
 	}
-	if proposal == nil || proposal.Error == nil {
+	if proposal == nil || !proposal.HasErrors() {
 		return nil
 	}
-	lines := bytes.Split(meta, []byte("\n"))
+	lines := bytes.Split(frontMatter.Metadata, []byte("\n"))
 
 	// using an object in a list context
 	matches := atsignRe.FindAllStringSubmatch(proposal.Error.Error(), -1)
@@ -401,21 +417,22 @@ func fixBareAtSign(path string, dryRun bool) error {
 	if dryRun {
 		return nil
 	}
-	if err := writeFile(path, head, bytes.Join(lines, []byte("\n")), body); err != nil {
+	frontMatter.Metadata = bytes.Join(lines, []byte("\n"))
+	if err := writeFile(path, frontMatter); err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
 func fixRawMarkdown(path string, dryRun bool) error {
-	proposal, head, meta, body, err := openProposal(path)
+	proposal, frontMatter, err := openProposal(path)
 	if err != nil {
 		return err
 	}
-	if proposal == nil || proposal.Error == nil {
+	if proposal == nil || !proposal.HasErrors() {
 		return nil
 	}
-	lines := bytes.Split(meta, []byte("\n"))
+	lines := bytes.Split(frontMatter.Metadata, []byte("\n"))
 
 	// markdown in raw yaml list...
 	matches := unexpectedHyphenRe.FindAllStringSubmatch(proposal.Error.Error(), -1)
@@ -434,7 +451,8 @@ func fixRawMarkdown(path string, dryRun bool) error {
 	if dryRun {
 		return nil
 	}
-	if err := writeFile(path, head, bytes.Join(lines, []byte("\n")), body); err != nil {
+	frontMatter.Metadata = bytes.Join(lines, []byte("\n"))
+	if err := writeFile(path, frontMatter); err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
@@ -467,14 +485,14 @@ func FixYAML(path string, dryRun bool) error {
 }
 
 func fixupStringListValuesToString(key, path string, dryRun bool) error {
-	proposal, head, meta, body, err := openProposal(path)
+	proposal, frontMatter, err := openProposal(path)
 	if err != nil {
 		return err
 	}
-	if proposal == nil || proposal.Error == nil {
+	if proposal == nil || !proposal.HasErrors() {
 		return nil
 	}
-	lines := bytes.Split(meta, []byte("\n"))
+	lines := bytes.Split(frontMatter.Metadata, []byte("\n"))
 
 	for _, line := range lines {
 		if bytes.HasPrefix(bytes.TrimSpace(line), []byte(key)) {
@@ -487,7 +505,8 @@ func fixupStringListValuesToString(key, path string, dryRun bool) error {
 	if dryRun {
 		return nil
 	}
-	if err := writeFile(path, head, bytes.Join(lines, []byte("\n")), body); err != nil {
+	frontMatter.Metadata = bytes.Join(lines, []byte("\n"))
+	if err := writeFile(path, frontMatter); err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
@@ -495,14 +514,14 @@ func fixupStringListValuesToString(key, path string, dryRun bool) error {
 
 // TODO: use this to ensure a required field, not editor
 func ensureEditor(path string, dryRun bool) error {
-	proposal, head, meta, body, err := openProposal(path)
+	proposal, frontMatter, err := openProposal(path)
 	if err != nil {
 		return err
 	}
-	if proposal == nil || proposal.Error == nil {
+	if proposal == nil || !proposal.HasErrors() {
 		return nil
 	}
-	lines := bytes.Split(meta, []byte("\n"))
+	lines := bytes.Split(frontMatter.Metadata, []byte("\n"))
 	foundEditor := false
 	for i, line := range lines {
 		if bytes.Contains(line, []byte("editor:")) {
@@ -521,21 +540,22 @@ func ensureEditor(path string, dryRun bool) error {
 	if dryRun {
 		return nil
 	}
-	if err := writeFile(path, head, bytes.Join(lines, []byte("\n")), body); err != nil {
+	frontMatter.Metadata = bytes.Join(lines, []byte("\n"))
+	if err := writeFile(path, frontMatter); err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
 func cleanTrailingWhitespace(path string, dryRun bool) error {
-	proposal, head, meta, body, err := openProposal(path)
+	proposal, frontMatter, err := openProposal(path)
 	if err != nil {
 		return err
 	}
-	if proposal == nil || proposal.Error == nil {
+	if proposal == nil || !proposal.HasErrors() {
 		return nil
 	}
-	lines := bytes.Split(meta, []byte("\n"))
+	lines := bytes.Split(frontMatter.Metadata, []byte("\n"))
 
 	for i, line := range lines {
 		lines[i] = bytes.TrimRightFunc(line, unicode.IsSpace)
@@ -544,21 +564,22 @@ func cleanTrailingWhitespace(path string, dryRun bool) error {
 	if dryRun {
 		return nil
 	}
-	if err := writeFile(path, head, bytes.Join(lines, []byte("\n")), body); err != nil {
+	frontMatter.Metadata = bytes.Join(lines, []byte("\n"))
+	if err := writeFile(path, frontMatter); err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
 func quoteUnquotedStringStartingWithAtSign(path string, dryRun bool) error {
-	proposal, head, meta, body, err := openProposal(path)
+	proposal, frontMatter, err := openProposal(path)
 	if err != nil {
 		return err
 	}
-	if proposal == nil || proposal.Error == nil {
+	if proposal == nil || !proposal.HasErrors() {
 		return nil
 	}
-	lines := bytes.Split(meta, []byte("\n"))
+	lines := bytes.Split(frontMatter.Metadata, []byte("\n"))
 
 	for i, line := range lines {
 		if valStartsWithAmpersand.Match(line) {
@@ -574,89 +595,151 @@ func quoteUnquotedStringStartingWithAtSign(path string, dryRun bool) error {
 	if dryRun {
 		return nil
 	}
-	if err := writeFile(path, head, bytes.Join(lines, []byte("\n")), body); err != nil {
+	frontMatter.Metadata = bytes.Join(lines, []byte("\n"))
+	if err := writeFile(path, frontMatter); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// fixTableOfContents regenerates the text between the toc markers from the
+// headings in the body.
+func fixTableOfContents(path string, dryRun bool) error {
+	_, frontMatter, err := openProposal(path)
+	if err != nil || frontMatter == nil {
+		return err
+	}
+	body := string(frontMatter.Body)
+	sections := keps.ParseSections(body, frontMatter.BodyLine())
+	updated, ok := keps.UpdateTableOfContents(body, sections)
+	if !ok || updated == body {
+		return nil
+	}
+	if dryRun {
+		fmt.Printf("%v: table of contents is out of date\n", path)
+		return nil
+	}
+	frontMatter.Body = []byte(updated)
+	if err := writeFile(path, frontMatter); err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
-func writeFile(path string, head, meta, body []byte) error {
-	w, err := os.OpenFile(path, os.O_RDWR|os.O_TRUNC, os.ModeAppend)
+// writeFile replaces the file at path with the reassembled front matter. The
+// new contents are written to a temporary file in the same directory and
+// renamed over the original so an interrupted write never leaves a truncated
+// KEP behind. The original permissions are kept. Files whose contents don't
+// change are left alone, and the rest are backed up before the first write.
+func writeFile(path string, frontMatter *keps.FrontMatter) error {
+	info, err := os.Stat(path)
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	defer w.Close()
-	if len(head) > 0 {
-		fmt.Fprint(w, string(head))
+	current, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	data := frontMatter.Bytes()
+	if bytes.Equal(current, data) {
+		return nil
+	}
+	if err := backups.save(path); err != nil {
+		return err
 	}
-	fmt.Fprintln(w, "---")
-	fmt.Fprint(w, string(meta))
-	fmt.Fprintln(w, "---")
+	return atomicWrite(path, data, info.Mode().Perm())
+}
+
+// backupPolicy copies each file to <file><suffix> before kepfix first edits
+// it, so the backup holds the file as it was before this run.
+type backupPolicy struct {
+	// suffix is appended to the path of each backup; no backups are made
+	// when it is empty.
+	suffix string
+	// overwrite replaces a backup that already exists instead of failing.
+	overwrite bool
+	// saved records the files backed up during this run.
+	saved map[string]bool
+}
+
+var backups = &backupPolicy{saved: map[string]bool{}}
 
-	fmt.Fprint(w, string(body))
+// save backs up the file at path unless it was already backed up during this
+// run. An existing backup is an error unless overwrite is set.
+func (b *backupPolicy) save(path string) error {
+	if b.suffix == "" || b.saved[path] {
+		return nil
+	}
+	backup := path + b.suffix
+	if _, err := os.Stat(backup); err == nil && !b.overwrite {
+		return errors.Errorf("backup %v already exists, pass -overwrite-backup to replace it", backup)
+	} else if err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	if err := backupFile(path, backup); err != nil {
+		return err
+	}
+	b.saved[path] = true
 	return nil
 }
 
-func extractData(reader io.Reader) ([]byte, []byte, []byte, error) {
-	scanner := bufio.NewScanner(reader)
-	count := 0
-	aboveTheHeader := []byte{}
-	metadata := []byte{}
-	restOfFile := []byte{}
-
-	whereAmI := "start"
-
-	// assume that the top of the file is the only yaml we want
-	for scanner.Scan() {
-		line := scanner.Text() + "\n"
-
-		if (count < 2 && strings.HasPrefix(line, "---")) ||
-			(count == 1 && strings.HasSuffix(strings.TrimSpace(line), "```")) {
-			count++
-			whereAmI = "metadata"
-			if count == 2 {
-				whereAmI = "body"
-			}
-			if strings.HasSuffix(strings.TrimSpace(line), "```") {
-				restOfFile = []byte("```\n")
-			}
-			continue
-		}
+// atomicWrite writes data to a temporary file next to path and renames it into
+// place.
+func atomicWrite(path string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// Removing the temporary file fails harmlessly once it has been renamed.
+	defer os.Remove(tmp.Name())
 
-		if whereAmI == "start" {
-			aboveTheHeader = append(aboveTheHeader, []byte(line)...)
-		} else if whereAmI == "metadata" {
-			metadata = append(metadata, []byte(line)...)
-		} else {
-			restOfFile = append(restOfFile, []byte(line)...)
-		}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.WithStack(err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return errors.WithStack(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.WithStack(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.WithStack(err)
 	}
-	if count != 2 {
-		return nil, nil, nil, errors.New("skip")
+	return errors.WithStack(os.Rename(tmp.Name(), path))
+}
+
+// backupFile copies the file at path to backup, keeping its permissions.
+func backupFile(path, backup string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.WithStack(err)
 	}
-	return aboveTheHeader, metadata, restOfFile, scanner.Err()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return atomicWrite(backup, data, info.Mode().Perm())
 }
 
-func openProposal(path string) (*keps.Proposal, []byte, []byte, []byte, error) {
-	// open file for writing
-	f, err := os.Open(path)
+func openProposal(path string) (*keps.Proposal, *keps.FrontMatter, error) {
+	content, err := ioutil.ReadFile(path)
 	if err != nil {
-		return nil, nil, nil, nil, errors.WithStack(err)
+		return nil, nil, errors.WithStack(err)
+	}
+	frontMatter, err := keps.SplitFrontMatter(content)
+	if err == keps.ErrNoFrontMatter || err == keps.ErrUnterminatedFrontMatter {
+		return nil, nil, nil
 	}
-	defer f.Close()
-	// read yaml into bytes
-	// read the rest of the file into bytes
-	head, metadata, body, err := extractData(f)
 	if err != nil {
-		if err.Error() == "skip" {
-			return nil, nil, nil, nil, nil
-		}
-		return nil, nil, nil, nil, errors.WithStack(err)
+		return nil, nil, errors.WithStack(err)
 	}
 
 	// parse yaml
 	proposal := &keps.Proposal{}
-	proposal.Error = yaml.Unmarshal(metadata, proposal)
+	proposal.Error = yaml.Unmarshal(frontMatter.Metadata, proposal)
 
-	return proposal, head, metadata, body, err
+	return proposal, frontMatter, nil
 }
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/chuckha/kepview/keps"
 )
 
 func TestMetadata(t *testing.T) {
@@ -33,3 +38,219 @@ func TestFieldKey(t *testing.T) {
 		t.Fatal("end needs to be 5 but was", end)
 	}
 }
+
+func TestWriteFile(t *testing.T) {
+	testcases := []struct {
+		name     string
+		original string
+		expected string
+	}{
+		{
+			"trailing newline is kept",
+			"---\ntitle: old\n---\nbody\n",
+			"---\ntitle: new\n---\nbody\n",
+		},
+		{
+			"missing trailing newline stays missing",
+			"---\ntitle: old\n---\nbody",
+			"---\ntitle: new\n---\nbody",
+		},
+		{
+			"delimiters and body are kept exactly",
+			"# header\n---\r\ntitle: old\n---\r\n| a |\n|---|\n",
+			"# header\n---\r\ntitle: new\n---\r\n| a |\n|---|\n",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "kepfix")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "kep.md")
+			if err := ioutil.WriteFile(path, []byte(tc.original), 0640); err != nil {
+				t.Fatal(err)
+			}
+			frontMatter, err := keps.SplitFrontMatter([]byte(tc.original))
+			if err != nil {
+				t.Fatal(err)
+			}
+			frontMatter.Metadata = []byte("title: new\n")
+			if err := writeFile(path, frontMatter); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			out, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tc.expected {
+				t.Fatalf("expected %q but got %q", tc.expected, string(out))
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0640 {
+				t.Fatalf("expected permissions 0640 but got %v", info.Mode().Perm())
+			}
+			files, err := ioutil.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != 1 {
+				t.Fatalf("expected only the KEP in %v but found %d files", dir, len(files))
+			}
+		})
+	}
+}
+
+func TestWriteFileMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kepfix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := writeFile(filepath.Join(dir, "missing.md"), &keps.FrontMatter{}); err == nil {
+		t.Fatal("expected an error writing a file that does not exist")
+	}
+}
+
+func TestBackupFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kepfix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "kep.md")
+	if err := ioutil.WriteFile(path, []byte("original"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := backupFile(path, path+".orig"); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	out, err := ioutil.ReadFile(path + ".orig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "original" {
+		t.Fatalf("expected backup to contain %q but got %q", "original", string(out))
+	}
+}
+
+func TestWriteFileBackup(t *testing.T) {
+	original := "---\ntitle: test\n---\nbody\n"
+	testcases := []struct {
+		name      string
+		writes    []string
+		existing  string
+		overwrite bool
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:   "unchanged",
+			writes: []string{original},
+		},
+		{
+			name:     "changed",
+			writes:   []string{"---\ntitle: fixed\n---\nbody\n"},
+			expected: original,
+		},
+		{
+			name:     "changed twice",
+			writes:   []string{"---\ntitle: fixed\n---\nbody\n", "---\ntitle: fixed again\n---\nbody\n"},
+			expected: original,
+		},
+		{
+			name:      "existing backup",
+			writes:    []string{"---\ntitle: fixed\n---\nbody\n"},
+			existing:  "older",
+			expected:  "older",
+			expectErr: true,
+		},
+		{
+			name:      "overwrite existing backup",
+			writes:    []string{"---\ntitle: fixed\n---\nbody\n"},
+			existing:  "older",
+			overwrite: true,
+			expected:  original,
+		},
+	}
+	defer func(b *backupPolicy) { backups = b }(backups)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "kepfix")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "kep.md")
+			if err := ioutil.WriteFile(path, []byte(original), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if tc.existing != "" {
+				if err := ioutil.WriteFile(path+".orig", []byte(tc.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			backups = &backupPolicy{suffix: ".orig", overwrite: tc.overwrite, saved: map[string]bool{}}
+			for _, contents := range tc.writes {
+				frontMatter, err := keps.SplitFrontMatter([]byte(contents))
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = writeFile(path, frontMatter)
+				if tc.expectErr && err == nil {
+					t.Fatal("expected an error")
+				}
+				if !tc.expectErr && err != nil {
+					t.Fatalf("did not expect an error: %+v", err)
+				}
+			}
+			out, err := ioutil.ReadFile(path + ".orig")
+			if tc.expected == "" {
+				if !os.IsNotExist(err) {
+					t.Fatalf("did not expect a backup but got %q", string(out))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tc.expected {
+				t.Fatalf("expected backup to contain %q but got %q", tc.expected, string(out))
+			}
+		})
+	}
+}
+
+func TestFixTableOfContents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kepfix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "kep.md")
+	original := "---\ntitle: test\n---\n# Test\n\n<!-- toc -->\n- [Old](#old)\n<!-- /toc -->\n\n## Summary\n\n### Goals\n"
+	if err := ioutil.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fixTableOfContents(path, true); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if out, _ := ioutil.ReadFile(path); string(out) != original {
+		t.Fatal("dry run should not edit the file")
+	}
+	if err := fixTableOfContents(path, false); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "---\ntitle: test\n---\n# Test\n\n<!-- toc -->\n- [Summary](#summary)\n  - [Goals](#goals)\n<!-- /toc -->\n\n## Summary\n\n### Goals\n"
+	if string(out) != expected {
+		t.Fatalf("expected %q but got %q", expected, string(out))
+	}
+}
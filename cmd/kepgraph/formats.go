@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/pkg/errors"
+)
+
+type writer func(io.Writer, *keps.Graph) error
+
+var formats = map[string]writer{
+	"dot":     writeDOT,
+	"mermaid": writeMermaid,
+	"json":    writeJSON,
+}
+
+func formatNames() string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, "|")
+}
+
+// label is what a node is called in diagrams.
+func label(n *keps.Node) string {
+	if n.Title == "" {
+		return n.ID
+	}
+	if n.Status == "" {
+		return n.Title
+	}
+	return fmt.Sprintf("%s (%s)", n.Title, n.Status)
+}
+
+// edgeStyles tell the kinds of edges apart in DOT.
+var edgeStyles = map[string]string{
+	keps.EdgeSeeAlso:      "dashed",
+	keps.EdgeReplaces:     "solid",
+	keps.EdgeSupersededBy: "bold",
+}
+
+func writeDOT(w io.Writer, g *keps.Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph keps {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		attrs := "label=" + strconv.Quote(label(n))
+		if n.Proposal == nil {
+			attrs += ", style=dotted"
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", strconv.Quote(n.ID), attrs)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s, style=%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(e.Kind), edgeStyles[e.Kind])
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return errors.WithStack(err)
+}
+
+// mermaidLabel escapes the characters Mermaid doesn't allow in quoted labels.
+var mermaidLabel = strings.NewReplacer(`"`, "#quot;", "\n", " ")
+
+func writeMermaid(w io.Writer, g *keps.Graph) error {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	ids := map[string]string{}
+	for i, n := range g.Nodes {
+		ids[n.ID] = "n" + strconv.Itoa(i)
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[n.ID], mermaidLabel.Replace(label(n)))
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Kind == keps.EdgeSeeAlso {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", ids[e.From], arrow, e.Kind, ids[e.To])
+	}
+	_, err := io.WriteString(w, b.String())
+	return errors.WithStack(err)
+}
+
+// jsonGraph is the JSON output: the nodes, the edges and which nodes each
+// node refers to.
+type jsonGraph struct {
+	Nodes     []*keps.Node        `json:"nodes"`
+	Edges     []keps.Edge         `json:"edges"`
+	Adjacency map[string][]string `json:"adjacency"`
+}
+
+func writeJSON(w io.Writer, g *keps.Graph) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.WithStack(enc.Encode(jsonGraph{Nodes: g.Nodes, Edges: g.Edges, Adjacency: g.Adjacency()}))
+}
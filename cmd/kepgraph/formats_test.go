@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+func testGraph() *keps.Graph {
+	return keps.BuildGraph(keps.Proposals{
+		{Title: `The "A" KEP`, Status: "implemented", Filename: "keps/sig-node/0001-a.md", OwningSIG: "sig-node", SeeAlso: []string{"0002-b"}},
+		{Title: "B", Filename: "keps/sig-node/0002-b.md", OwningSIG: "node", Replaces: []string{"0003-c"}},
+		{Title: "C", Filename: "keps/sig-network/0003-c.md", OwningSIG: "sig-network", SupersededBy: []string{"gone.md"}},
+	}, "keps")
+}
+
+func TestFormats(t *testing.T) {
+	testcases := []struct {
+		format   string
+		expected []string
+	}{
+		{"dot", []string{
+			`"sig-node/0001-a" [label="The \"A\" KEP (implemented)"];`,
+			`"gone.md" [label="gone.md", style=dotted];`,
+			`"sig-node/0001-a" -> "sig-node/0002-b" [label="see-also", style=dashed];`,
+		}},
+		{"mermaid", []string{
+			"graph LR\n",
+			`n2["The #quot;A#quot; KEP (implemented)"]`,
+			"n2 -.->|see-also| n3",
+			"n3 -->|replaces| n1",
+		}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := formats[tc.format](&buf, testGraph()); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			for _, e := range tc.expected {
+				if !strings.Contains(buf.String(), e) {
+					t.Fatalf("expected output to contain %q but got:\n%s", e, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, testGraph()); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	var out jsonGraph
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Nodes) != 4 || len(out.Edges) != 3 {
+		t.Fatalf("unexpected graph %+v", out)
+	}
+	if to := out.Adjacency["sig-node/0002-b"]; len(to) != 1 || to[0] != "sig-network/0003-c" {
+		t.Fatalf("unexpected adjacency %v", out.Adjacency)
+	}
+}
+
+func TestFilterGraph(t *testing.T) {
+	config := validations.DefaultConfig()
+	config.SetSIGRegistry(validations.NewSIGRegistry("sig-node", "sig-network"))
+	g, err := filterGraph(testGraph(), config, "sig-node", "", 0)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(g.Nodes) != 2 || len(g.Edges) != 1 {
+		t.Fatalf("expected the two sig-node KEPs but got %v", g.Nodes)
+	}
+
+	g, err = filterGraph(testGraph(), config, "", "0003", 1)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(g.Nodes) != 3 || len(g.Edges) != 2 {
+		t.Fatalf("expected C and its neighbours but got %v", g.Nodes)
+	}
+
+	if _, err := filterGraph(testGraph(), config, "", "9999", 1); err == nil {
+		t.Fatal("expected an error for an unknown KEP")
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	list := flag.NewFlagSet("list", flag.ExitOnError)
+	root := list.String("keps", ".", "the location of the keps directory")
+	format := list.String("format", "dot", "output format: "+formatNames())
+	sig := list.String("sig", "", "only show KEPs owned by this SIG")
+	from := list.String("from", "", "only show KEPs related to this KEP, given as its path, file name or number")
+	depth := list.Int("depth", 0, "with -from, how many relationships away to go (0 for no limit)")
+	configPath := list.String("config", "", "the project configuration file (defaults to the nearest "+validations.ConfigFilename+")")
+	list.Parse(os.Args[1:])
+
+	write, ok := formats[*format]
+	if !ok {
+		fmt.Printf("unknown format %q, must be one of %s\n", *format, formatNames())
+		os.Exit(2)
+	}
+	if err := run(*root, *configPath, *sig, *from, *depth, write); err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(2)
+	}
+}
+
+func run(root, configPath, sig, from string, depth int, write writer) error {
+	config, err := validations.LoadConfig(configPath, root)
+	if err != nil {
+		return err
+	}
+	var proposals keps.Proposals
+	finder := keps.NewEnhancementFinder(keps.WithParser(&keps.Parser{Config: config}))
+	if err := filepath.Walk(root, finder.Find(&proposals)); err != nil {
+		return err
+	}
+	g, err := filterGraph(keps.BuildGraph(proposals, root), config, sig, from, depth)
+	if err != nil {
+		return err
+	}
+	return write(os.Stdout, g)
+}
+
+// filterGraph keeps the KEPs owned by sig and those within depth of from.
+func filterGraph(g *keps.Graph, config *validations.Config, sig, from string, depth int) (*keps.Graph, error) {
+	var near map[*keps.Node]bool
+	if from != "" {
+		start := g.Node(from)
+		if start == nil {
+			return nil, errors.Errorf("no KEP matches %q", from)
+		}
+		near = g.Neighbourhood(start, depth)
+	}
+	if sig != "" {
+		sig = config.CanonicalSIG(sig)
+	}
+	return g.Subgraph(func(n *keps.Node) bool {
+		if near != nil && !near[n] {
+			return false
+		}
+		if sig != "" && config.CanonicalSIG(n.SIG) != sig {
+			return false
+		}
+		return true
+	}), nil
+}
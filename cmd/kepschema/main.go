@@ -0,0 +1,54 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	list := flag.NewFlagSet("list", flag.ExitOnError)
+	configPath := list.String("config", "", "the project configuration file (defaults to the nearest "+validations.ConfigFilename+")")
+	id := list.String("id", "", "the $id of the generated schema")
+	list.Parse(os.Args[1:])
+
+	config, err := validations.LoadConfig(*configPath, ".")
+	if err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(2)
+	}
+	if err := writeSchema(os.Stdout, config, *id); err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(2)
+	}
+}
+
+func writeSchema(w io.Writer, config *validations.Config, id string) error {
+	schema := keps.Schema(config)
+	schema.ID = id
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.WithStack(enc.Encode(schema))
+}
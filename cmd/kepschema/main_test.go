@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+func TestWriteSchema(t *testing.T) {
+	config := validations.DefaultConfig()
+	config.Keys["status"].Required = true
+
+	var out bytes.Buffer
+	if err := writeSchema(&out, config, "https://example.com/kep.json"); err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		ID         string                     `json:"$id"`
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &schema); err != nil {
+		t.Fatalf("expected JSON but got %v:\n%s", err, out.String())
+	}
+	if schema.ID != "https://example.com/kep.json" {
+		t.Fatalf("expected the $id to be set but got %q", schema.ID)
+	}
+	if len(schema.Required) != 1 || schema.Required[0] != "status" {
+		t.Fatalf("expected status to be required but got %v", schema.Required)
+	}
+	if _, ok := schema.Properties["title"]; !ok {
+		t.Fatalf("expected a title property but got %v", schema.Properties)
+	}
+}
+
+func TestWriteSchemaWithoutID(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeSchema(&out, validations.DefaultConfig(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out.Bytes(), []byte(`"$id"`)) {
+		t.Fatalf("expected no $id but got:\n%s", out.String())
+	}
+}
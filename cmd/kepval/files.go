@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/pkg/errors"
+)
+
+// skip returns true if the filename matches one of the KEP filename filters.
+func skip(name string) bool {
+	for _, f := range keps.DefaultFilenameFilters() {
+		if f.Filter(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandPaths turns the command line arguments into the list of KEP files to
+// validate. Files are used as given, directories are walked and globs are
+// expanded. Files found by walking or globbing are skipped if they don't look
+// like KEPs.
+func expandPaths(args []string) ([]string, error) {
+	var paths []string
+	seen := map[string]bool{}
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "*?[") {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, errors.Wrapf(err, "bad pattern %q", arg)
+			}
+			for _, match := range matches {
+				if hiddenMatch(arg, match) {
+					continue
+				}
+				found, err := walk(match)
+				if err != nil {
+					return nil, err
+				}
+				for _, f := range found {
+					add(f)
+				}
+			}
+			continue
+		}
+		info, err := os.Stat(arg)
+		if err != nil || !info.IsDir() {
+			// let validation report files that can't be opened
+			add(arg)
+			continue
+		}
+		found, err := walk(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range found {
+			add(f)
+		}
+	}
+	return paths, nil
+}
+
+// hiddenMatch returns true if a wildcard in pattern matched a hidden file or
+// directory, which shells leave out of glob expansions.
+func hiddenMatch(pattern, match string) bool {
+	patterns := strings.Split(filepath.ToSlash(pattern), "/")
+	matches := strings.Split(filepath.ToSlash(match), "/")
+	for i := range matches {
+		if i < len(patterns) && strings.HasPrefix(matches[i], ".") && !strings.HasPrefix(patterns[i], ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// walk returns the KEP files under root, skipping hidden directories.
+func walk(root string) ([]string, error) {
+	var paths []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if skip(info.Name()) {
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	return paths, err
+}
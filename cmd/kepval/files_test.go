@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kepview/keps"
+)
+
+func TestExpandPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kepval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{
+		"keps/README.md",
+		"keps/NNNN-kep-template.md",
+		"keps/sig-foo/0001-a.md",
+		"keps/sig-foo/OWNERS",
+		"keps/sig-bar/0002-b.md",
+		"keps/.hidden/0003-c.md",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte("---\n---\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keps := filepath.Join(dir, "keps")
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			"directory",
+			[]string{keps},
+			[]string{filepath.Join(keps, "sig-bar", "0002-b.md"), filepath.Join(keps, "sig-foo", "0001-a.md")},
+		},
+		{
+			"glob",
+			[]string{filepath.Join(keps, "*", "*.md")},
+			[]string{filepath.Join(keps, "sig-bar", "0002-b.md"), filepath.Join(keps, "sig-foo", "0001-a.md")},
+		},
+		{
+			"explicit files are not filtered",
+			[]string{filepath.Join(keps, "README.md"), filepath.Join(keps, "missing.md")},
+			[]string{filepath.Join(keps, "README.md"), filepath.Join(keps, "missing.md")},
+		},
+		{
+			"duplicates are removed",
+			[]string{filepath.Join(keps, "sig-foo"), filepath.Join(keps, "sig-foo", "0001-a.md")},
+			[]string{filepath.Join(keps, "sig-foo", "0001-a.md")},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			paths, err := expandPaths(tc.args)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if !reflect.DeepEqual(paths, tc.expected) {
+				t.Fatalf("expected %v but got %v", tc.expected, paths)
+			}
+		})
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	parser := &keps.Parser{}
+	kep, err := parseFile(parser, "-", strings.NewReader("---\ntitle: from stdin\n---\n"))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if kep.Title != "from stdin" {
+		t.Fatalf("expected to parse stdin but got %q", kep.Title)
+	}
+	if _, err := parseFile(parser, filepath.Join(os.TempDir(), "does-not-exist.md"), nil); err == nil {
+		t.Fatal("expected an error opening a missing file")
+	}
+}
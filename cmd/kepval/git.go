@@ -0,0 +1,137 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// changeSet is the set of KEP files that differ from a base commit.
+type changeSet struct {
+	// base is the commit the changes are compared against.
+	base string
+	// root is the top of the git checkout.
+	root string
+	// wd is the working directory kepval was run from.
+	wd string
+	// files maps the changed files, relative to the working directory, to
+	// their path relative to the root of the checkout.
+	files map[string]string
+}
+
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// changedSince finds the KEP files in the working tree that differ from the
+// merge base of ref and HEAD. Deleted files are left out.
+func changedSince(ref string) (*changeSet, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	out, err := git(wd, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root := strings.TrimSpace(string(out))
+	out, err = git(wd, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	cs := &changeSet{
+		base:  strings.TrimSpace(string(out)),
+		root:  root,
+		wd:    wd,
+		files: map[string]string{},
+	}
+	out, err = git(root, "diff", "--name-only", "--no-renames", "--diff-filter=ACM", cs.base)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if name == "" || skip(filepath.Base(name)) {
+			continue
+		}
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if rel, err := filepath.Rel(wd, path); err == nil {
+			path = rel
+		}
+		cs.files[path] = name
+	}
+	return cs, nil
+}
+
+// filter keeps the paths that are part of the change set, along with stdin.
+// With no paths it returns every changed file.
+func (c *changeSet) filter(paths []string) []string {
+	var out []string
+	if len(paths) == 0 {
+		for path := range c.files {
+			out = append(out, path)
+		}
+		return out
+	}
+	for _, path := range paths {
+		if path == "-" {
+			out = append(out, path)
+			continue
+		}
+		if _, ok := c.files[c.key(path)]; ok {
+			out = append(out, path)
+		}
+	}
+	return out
+}
+
+// baseVersion returns the contents of path at the base commit, or false if
+// the file did not exist then.
+func (c *changeSet) baseVersion(path string) ([]byte, bool) {
+	name, ok := c.files[c.key(path)]
+	if !ok {
+		return nil, false
+	}
+	out, err := git(c.root, "show", c.base+":"+name)
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// key returns path relative to the working directory.
+func (c *changeSet) key(path string) string {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(c.wd, path); err == nil {
+			return rel
+		}
+	}
+	return filepath.Clean(path)
+}
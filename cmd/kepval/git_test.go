@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+const baseKEP = `---
+title: test
+status: provisional
+last-updated: 2019-01-01
+---
+`
+
+// customKEP is only valid with the configuration in TestChangedSince.
+const customKEP = `---
+title: test
+reviewers: []
+status: provisional
+---
+`
+
+func TestChangedSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "kepval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, contents string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(args ...string) {
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		if _, err := git(dir, args...); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+
+	write("keps/sig-foo/0001-changed.md", baseKEP)
+	write("keps/sig-foo/0002-unchanged.md", baseKEP)
+	write("keps/sig-foo/0004-custom.md", customKEP)
+	write(".kepval.yaml", "keys:\n  title:\n    type: string\n  status:\n    type: string\n  reviewers:\n    type: list\n    allowEmpty: true\n")
+	run("init", "-q")
+	run("add", ".")
+	run("commit", "-q", "-m", "base")
+	write("keps/sig-foo/0001-changed.md", strings.NewReplacer("provisional", "implemented", "2019-01-01", "2018-12-01").Replace(baseKEP))
+	write("keps/sig-foo/0003-new.md", baseKEP)
+	write("keps/sig-foo/0004-custom.md", strings.Replace(customKEP, "provisional", "implemented", 1))
+	write("keps/sig-foo/README.md", "readme")
+	run("add", ".")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := changedSince("HEAD")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	changed := filepath.Join("keps", "sig-foo", "0001-changed.md")
+	added := filepath.Join("keps", "sig-foo", "0003-new.md")
+	files := changes.filter(nil)
+	if len(files) != 3 {
+		t.Fatalf("expected 3 changed KEPs but got %v", files)
+	}
+	if files := changes.filter([]string{changed, filepath.Join("keps", "sig-foo", "0002-unchanged.md")}); len(files) != 1 || files[0] != changed {
+		t.Fatalf("expected only %v but got %v", changed, files)
+	}
+	if _, ok := changes.baseVersion(added); ok {
+		t.Fatal("did not expect a base version of a new KEP")
+	}
+
+	parser := &keps.Parser{}
+	f, err := os.Open(changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	kep := parser.Parse(f)
+	kep.Filename = changed
+	errs, ok := baseCheck(changes, validations.DefaultConfig())(kep).(validations.ErrorList)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("expected a status and last-updated error but got %v", errs)
+	}
+	if errs[0].Code() != "invalid-status-transition" || errs[0].Line != 3 {
+		t.Fatalf("unexpected error %v", errs[0])
+	}
+	if errs[1].Code() != "last-updated-not-bumped" || errs[1].Line != 4 {
+		t.Fatalf("unexpected error %v", errs[1])
+	}
+
+	// The base version is parsed with the configured rules, so a KEP that is
+	// only valid with them still has its status transition checked.
+	config, err := validations.LoadConfig(".kepval.yaml", "")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	custom := filepath.Join("keps", "sig-foo", "0004-custom.md")
+	f, err = os.Open(custom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	kep = (&keps.Parser{Config: config}).Parse(f)
+	kep.Filename = custom
+	errs, ok = baseCheck(changes, config)(kep).(validations.ErrorList)
+	if !ok || len(errs) != 1 || errs[0].Code() != "invalid-status-transition" {
+		t.Fatalf("expected a status transition error but got %v", errs)
+	}
+}
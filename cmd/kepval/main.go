@@ -17,38 +17,155 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 
 	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+	"github.com/pkg/errors"
 )
 
 func main() {
 	list := flag.NewFlagSet("list", flag.ExitOnError)
+	checkSections := list.Bool("sections", false, "check the KEP body has the sections required for its status")
+	checkTOC := list.Bool("toc", false, "check the table of contents matches the headings")
+	checkLinks := list.Bool("links", false, "check relative links and anchors in the KEP body resolve")
+	external := list.Bool("external", false, "with -links, also check http and https links over the network")
+	checkOwners := list.Bool("owners", false, "check the approvers can approve the owning SIG's directory according to the OWNERS files")
+	root := list.String("root", "", "with -links or -owners, the root of the checkout holding absolute link targets and OWNERS_ALIASES (defaults to the git checkout)")
+	format := list.String("format", "text", "output format: "+formatNames())
+	stdinFilename := list.String("stdin-filename", "", "the filename to report and resolve links from when reading a KEP from stdin with -")
+	configPath := list.String("config", "", "the project configuration file (defaults to the nearest "+validations.ConfigFilename+")")
+	schemaPath := list.String("schema", "", "a JSON Schema the KEP metadata must match, overriding the schema in the configuration file")
+	sigsPath := list.String("sigs", "", "a kubernetes/community sigs.yaml listing the SIGs KEPs may name, overriding the configuration file")
+	failOn := list.String("fail-on", "error", "the least severe result that fails validation: error or warning")
+	changedSinceRef := list.String("changed-since", "", "only validate KEPs changed since this git ref and check their status and last-updated against it")
 	list.Parse(os.Args[1:])
 
-	parser := &keps.Parser{}
-	exit := 0
-	for _, filename := range list.Args() {
-		file, err := os.Open(filename)
+	write, ok := formats[*format]
+	if !ok {
+		fmt.Printf("unknown format %q, must be one of %s\n", *format, formatNames())
+		os.Exit(2)
+	}
+
+	failSeverity, err := validations.ParseSeverity(*failOn)
+	if err != nil || failSeverity == validations.SeverityInfo {
+		fmt.Printf("unknown -fail-on %q, must be error or warning\n", *failOn)
+		os.Exit(2)
+	}
+
+	config, err := validations.LoadConfig(*configPath, ".")
+	if err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(2)
+	}
+	if *schemaPath != "" {
+		schema, err := validations.LoadSchema(*schemaPath)
+		if err != nil {
+			fmt.Printf("%+v", err)
+			os.Exit(2)
+		}
+		config.SetSchema(schema)
+	}
+	if *sigsPath != "" {
+		sigs, err := validations.LoadSIGRegistry(*sigsPath)
 		if err != nil {
-			fmt.Printf("could not open file: %v", err)
-			os.Exit(1)
+			fmt.Printf("%+v", err)
+			os.Exit(2)
 		}
-		defer file.Close()
-		kep := parser.Parse(file)
-		// if error is nil we can move on
-		if kep.Error == nil {
+		config.SetSIGRegistry(sigs)
+	}
+
+	var checks []keps.Check
+	if *checkSections {
+		checks = append(checks, func(p *keps.Proposal) error { return p.ValidateSections(config) })
+	}
+	if *checkTOC {
+		checks = append(checks, (*keps.Proposal).ValidateTableOfContents)
+	}
+	if *checkLinks {
+		linkChecker := keps.NewLinkChecker(*root)
+		linkChecker.External = *external
+		checks = append(checks, linkChecker.Check)
+	}
+	if *checkOwners {
+		checks = append(checks, keps.NewOwnersChecker(*root).Check)
+	}
+
+	filenames, err := expandPaths(list.Args())
+	if err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(2)
+	}
+	if *changedSinceRef != "" {
+		changes, err := changedSince(*changedSinceRef)
+		if err != nil {
+			fmt.Printf("%+v", err)
+			os.Exit(2)
+		}
+		filenames = changes.filter(filenames)
+		sort.Strings(filenames)
+		checks = append(checks, baseCheck(changes, config))
+	}
+
+	parser := &keps.Parser{Config: config}
+	r := &report{failOn: failSeverity}
+	for _, filename := range filenames {
+		name := filename
+		if filename == "-" {
+			name = *stdinFilename
+			if name == "" {
+				name = "<stdin>"
+			}
+		}
+		kep, err := parseFile(parser, filename, os.Stdin)
+		if err != nil {
+			r.add(name, errors.Wrap(err, "could not open file"))
 			continue
 		}
+		kep.Filename = name
+		r.add(name, kep.Validate(checks, config))
+	}
 
-		fmt.Printf("%v has an error: %q\n", filename, kep.Error.Error())
-		exit = 1
+	if err := write(os.Stdout, r); err != nil {
+		fmt.Printf("%+v", err)
+		os.Exit(2)
+	}
+	if r.count(failSeverity) > 0 {
+		os.Exit(1)
 	}
+}
 
-	if exit == 0 {
-		fmt.Println("No validation errors")
+// parseFile parses the KEP in filename, or the one on stdin if filename is "-".
+func parseFile(parser *keps.Parser, filename string, stdin io.Reader) (*keps.Proposal, error) {
+	if filename == "-" {
+		return parser.Parse(stdin), nil
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parser.Parse(file), nil
+}
+
+// baseCheck compares each KEP with its version at the base of the change set,
+// parsed with the same rules.
+func baseCheck(changes *changeSet, config *validations.Config) keps.Check {
+	parser := &keps.Parser{Config: config}
+	return func(kep *keps.Proposal) error {
+		contents, ok := changes.baseVersion(kep.Filename)
+		if !ok {
+			return nil
+		}
+		base := parser.Parse(bytes.NewReader(contents))
+		if base.HasErrors() {
+			return nil
+		}
+		return kep.ValidateChanges(base)
 	}
-	os.Exit(exit)
 }
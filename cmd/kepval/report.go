@@ -0,0 +1,386 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chuckha/kepview/keps/validations"
+	"github.com/pkg/errors"
+)
+
+// result is a single validation error in a KEP file.
+type result struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// report collects the results of validating a set of files.
+type report struct {
+	files   []string
+	results []result
+	// failOn is the least severe result that fails a file.
+	failOn validations.Severity
+}
+
+// add records that filename was validated along with any error it had.
+func (r *report) add(filename string, err error) {
+	r.files = append(r.files, filename)
+	if err == nil {
+		return
+	}
+	errs, ok := err.(validations.ErrorList)
+	if !ok {
+		r.results = append(r.results, result{
+			File:     filename,
+			Code:     "invalid-kep",
+			Severity: validations.SeverityError.String(),
+			Message:  err.Error(),
+		})
+		return
+	}
+	start := len(r.results)
+	for _, e := range errs {
+		r.results = append(r.results, result{
+			File:     filename,
+			Line:     e.Line,
+			Column:   e.Column,
+			Code:     e.Code(),
+			Severity: e.Severity.String(),
+			Message:  e.Err.Error(),
+		})
+	}
+	// The checks run one after another, so a file's results are put in the
+	// order they appear in the file for every format.
+	added := r.results[start:]
+	sort.SliceStable(added, func(i, j int) bool {
+		if added[i].Line != added[j].Line {
+			return added[i].Line < added[j].Line
+		}
+		return added[i].Column < added[j].Column
+	})
+}
+
+// count returns the number of results at least as severe as s.
+func (r *report) count(s validations.Severity) int {
+	n := 0
+	for _, res := range r.results {
+		severity, _ := validations.ParseSeverity(res.Severity)
+		if severity <= s {
+			n++
+		}
+	}
+	return n
+}
+
+// failed returns true if any result for filename fails the run.
+func (r *report) failed(filename string) bool {
+	for _, res := range r.resultsFor(filename) {
+		severity, _ := validations.ParseSeverity(res.Severity)
+		if severity <= r.failOn {
+			return true
+		}
+	}
+	return false
+}
+
+// resultsFor returns the results for a single file.
+func (r *report) resultsFor(filename string) []result {
+	var out []result
+	for _, res := range r.results {
+		if res.File == filename {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+type writer func(io.Writer, *report) error
+
+var formats = map[string]writer{
+	"text":   writeText,
+	"json":   writeJSON,
+	"sarif":  writeSARIF,
+	"github": writeGitHub,
+	"junit":  writeJUnit,
+}
+
+func formatNames() string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, "|")
+}
+
+// article returns the severity with its indefinite article for text output.
+func article(severity string) string {
+	switch severity {
+	case "warning":
+		return "a warning"
+	case "info":
+		return "a note"
+	}
+	return "an error"
+}
+
+func writeText(w io.Writer, r *report) error {
+	for _, res := range r.results {
+		switch {
+		case res.Line == 0:
+			fmt.Fprintf(w, "%v has %s: %q\n", res.File, article(res.Severity), res.Message)
+		case res.Column == 0:
+			fmt.Fprintf(w, "%v:%d has %s: %q\n", res.File, res.Line, article(res.Severity), res.Message)
+		default:
+			fmt.Fprintf(w, "%v:%d:%d has %s: %q\n", res.File, res.Line, res.Column, article(res.Severity), res.Message)
+		}
+	}
+	if len(r.results) == 0 {
+		fmt.Fprintln(w, "No validation errors")
+	}
+	if len(r.files) < 2 {
+		return nil
+	}
+	failed := 0
+	for _, file := range r.files {
+		if r.failed(file) {
+			failed++
+		}
+		if n := len(r.resultsFor(file)); n > 0 {
+			fmt.Fprintf(w, "%v: %d problem(s)\n", file, n)
+		}
+	}
+	errs := r.count(validations.SeverityError)
+	warnings := r.count(validations.SeverityWarning) - errs
+	fmt.Fprintf(w, "Validated %d file(s): %d failed, %d error(s) and %d warning(s) in total\n", len(r.files), failed, errs, warnings)
+	return nil
+}
+
+func writeJSON(w io.Writer, r *report) error {
+	results := r.results
+	if results == nil {
+		results = []result{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.WithStack(enc.Encode(results))
+}
+
+// The subset of SARIF 2.1.0 needed to report results.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case "warning":
+		return "warning"
+	case "info":
+		return "note"
+	}
+	return "error"
+}
+
+func writeSARIF(w io.Writer, r *report) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "kepval",
+			InformationURI: "https://github.com/chuckha/kepview",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	seen := map[string]bool{}
+	for _, res := range r.results {
+		if !seen[res.Code] {
+			seen[res.Code] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: res.Code})
+		}
+		line := res.Line
+		if line < 1 {
+			line = 1
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  res.Code,
+			Level:   sarifLevel(res.Severity),
+			Message: sarifMessage{Text: res.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(res.File)},
+					Region:           sarifRegion{StartLine: line, StartColumn: res.Column},
+				},
+			}},
+		})
+	}
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.WithStack(enc.Encode(log))
+}
+
+// GitHub Actions workflow commands need %, CR and LF escaped in messages and
+// additionally : and , escaped in properties.
+var (
+	githubData     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func githubCommand(severity string) string {
+	switch severity {
+	case "warning":
+		return "warning"
+	case "info":
+		return "notice"
+	}
+	return "error"
+}
+
+func writeGitHub(w io.Writer, r *report) error {
+	for _, res := range r.results {
+		props := []string{"file=" + githubProperty.Replace(filepath.ToSlash(res.File))}
+		if res.Line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", res.Line))
+		}
+		if res.Column > 0 {
+			props = append(props, fmt.Sprintf("col=%d", res.Column))
+		}
+		props = append(props, "title="+githubProperty.Replace(res.Code))
+		fmt.Fprintf(w, "::%s %s::%s\n", githubCommand(res.Severity), strings.Join(props, ","), githubData.Replace(res.Message))
+	}
+	return nil
+}
+
+// The subset of the JUnit XML format understood by most test report UIs.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, r *report) error {
+	suite := junitTestSuite{Name: "kepval", Tests: len(r.files)}
+	for _, file := range r.files {
+		tc := junitTestCase{Name: file, ClassName: "kepval"}
+		results := r.resultsFor(file)
+		if r.failed(file) {
+			suite.Failures++
+			var text strings.Builder
+			for _, res := range results {
+				fmt.Fprintf(&text, "%s:%d:%d: %s: %s: %s\n", res.File, res.Line, res.Column, res.Severity, res.Code, res.Message)
+			}
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d validation problem(s)", len(results)),
+				Type:    results[0].Code,
+				Text:    text.String(),
+			}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.WithStack(err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return errors.WithStack(err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return errors.WithStack(err)
+}
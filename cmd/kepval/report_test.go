@@ -0,0 +1,164 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kepview/keps/validations"
+	"github.com/pkg/errors"
+)
+
+func testReport() *report {
+	r := &report{}
+	r.add("keps/good.md", nil)
+	r.add("keps/bad.md", validations.ErrorList{
+		{Line: 3, Column: 1, Err: &validations.InvalidYAML{}},
+	})
+	r.add("keps/unreadable.md", errors.New("error reading file: 100%, really\nbroken"))
+	return r
+}
+
+func TestReportFormats(t *testing.T) {
+	testcases := []struct {
+		format   string
+		expected []string
+	}{
+		{"text", []string{`keps/bad.md:3:1 has an error: ""`, `keps/unreadable.md has an error:`}},
+		{"github", []string{
+			"::error file=keps/bad.md,line=3,col=1,title=invalid-yaml::\n",
+			"::error file=keps/unreadable.md,title=invalid-kep::error reading file: 100%25, really%0Abroken\n",
+		}},
+		{"sarif", []string{`"ruleId": "invalid-yaml"`, `"uri": "keps/bad.md"`, `"startLine": 3`}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := formats[tc.format](&buf, testReport()); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			for _, e := range tc.expected {
+				if !strings.Contains(buf.String(), e) {
+					t.Fatalf("expected output to contain %q but got:\n%s", e, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestTextReportOrder(t *testing.T) {
+	// Body checks run after the metadata warnings but come earlier in the file.
+	r := &report{}
+	r.add("keps/kep.md", validations.ErrorList{
+		{Line: 4, Column: 1, Severity: validations.SeverityWarning, Err: errors.New("metadata")},
+		{Line: 2, Severity: validations.SeverityWarning, Err: errors.New("front matter")},
+		{Line: 4, Severity: validations.SeverityWarning, Err: errors.New("metadata line")},
+		{Err: errors.New("whole file")},
+	})
+	var buf bytes.Buffer
+	if err := writeText(&buf, r); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	expected := `keps/kep.md has an error: "whole file"
+keps/kep.md:2 has a warning: "front matter"
+keps/kep.md:4 has a warning: "metadata line"
+keps/kep.md:4:1 has a warning: "metadata"
+`
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, buf.String())
+	}
+}
+
+func TestJSONReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, testReport()); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	var results []result
+	if err := json.Unmarshal(buf.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results but got %v", results)
+	}
+	if results[0].Code != "invalid-yaml" || results[0].Severity != "error" || results[0].Line != 3 {
+		t.Fatalf("unexpected result %+v", results[0])
+	}
+
+	buf.Reset()
+	if err := writeJSON(&buf, &report{}); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Fatalf("expected an empty list but got %q", buf.String())
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeJUnit(&buf, testReport()); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatal(err)
+	}
+	suite := suites.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 2 {
+		t.Fatalf("expected 3 tests and 2 failures but got %d and %d", suite.Tests, suite.Failures)
+	}
+	if suite.Cases[0].Failure != nil {
+		t.Fatal("expected the good KEP to pass")
+	}
+}
+
+func TestFailOn(t *testing.T) {
+	warnings := validations.ErrorList{
+		{Line: 2, Severity: validations.SeverityWarning, Err: &validations.MissingEditor{}},
+	}
+	testcases := []struct {
+		failOn   validations.Severity
+		failures int
+	}{
+		{validations.SeverityError, 0},
+		{validations.SeverityWarning, 1},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.failOn.String(), func(t *testing.T) {
+			r := &report{failOn: tc.failOn}
+			r.add("keps/warned.md", warnings)
+			if r.failed("keps/warned.md") != (tc.failures > 0) {
+				t.Fatalf("unexpected failure status for -fail-on %v", tc.failOn)
+			}
+			var buf bytes.Buffer
+			if err := writeJUnit(&buf, r); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			var suites junitTestSuites
+			if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+				t.Fatal(err)
+			}
+			if suites.Suites[0].Failures != tc.failures {
+				t.Fatalf("expected %d failures but got %d", tc.failures, suites.Suites[0].Failures)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+	"github.com/pkg/errors"
+)
+
+const (
+	apiPrefix = "/api/v1"
+	// defaultLimit and maxLimit bound the KEPs in a page of results.
+	defaultLimit = 50
+	maxLimit     = 500
+	// maxKEPSize is the largest KEP /validate accepts.
+	maxKEPSize = 1 << 20
+)
+
+var requestNumberRe = regexp.MustCompile(`^(?i:kep-?)?0*([0-9]+)$`)
+
+func (s *server) apiRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(apiPrefix+"/keps", s.handleAPIKEPs)
+	mux.HandleFunc(apiPrefix+"/keps/", s.handleAPIKEP)
+	mux.HandleFunc(apiPrefix+"/sigs", s.handleAPISIGs)
+	mux.HandleFunc(apiPrefix+"/people", s.handleAPIPeople)
+	mux.HandleFunc(apiPrefix+"/validate", s.handleAPIValidate)
+	mux.HandleFunc(apiPrefix+"/events", s.handleAPIEvents)
+	mux.HandleFunc(apiPrefix+"/openapi.json", s.handleAPIOpenAPI)
+}
+
+// kepPage is a page of the KEPs matching a query.
+type kepPage struct {
+	Total  int           `json:"total"`
+	Offset int           `json:"offset"`
+	Limit  int           `json:"limit"`
+	KEPs   []*kepSummary `json:"keps"`
+}
+
+// validation is the result of validating a KEP.
+type validation struct {
+	Valid    bool      `json:"valid"`
+	Problems []problem `json:"problems"`
+}
+
+// paginate reads the offset and limit query parameters.
+func paginate(params url.Values) (offset, limit int, err error) {
+	offset, limit = 0, defaultLimit
+	if v := params.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, errors.Errorf("invalid offset %q, must be a number of KEPs to skip", v)
+		}
+	}
+	if v := params.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxLimit {
+			return 0, 0, errors.Errorf("invalid limit %q, must be between 1 and %d", v, maxLimit)
+		}
+	}
+	return offset, limit, nil
+}
+
+func (s *server) handleAPIKEPs(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, "GET", "HEAD") {
+		return
+	}
+	params := r.URL.Query()
+	offset, limit, err := paginate(params)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	summaries, err := s.query(params)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	page := &kepPage{Total: len(summaries), Offset: offset, Limit: limit, KEPs: []*kepSummary{}}
+	if offset < len(summaries) {
+		end := offset + limit
+		if end > len(summaries) {
+			end = len(summaries)
+		}
+		page.KEPs = summaries[offset:end]
+	}
+	if offset+limit < len(summaries) {
+		params.Set("offset", strconv.Itoa(offset+limit))
+		params.Set("limit", strconv.Itoa(limit))
+		next := url.URL{Path: r.URL.Path, RawQuery: params.Encode()}
+		w.Header().Set("Link", "<"+next.String()+`>; rel="next"`)
+	}
+	writeJSON(w, r, http.StatusOK, page)
+}
+
+// handleAPIKEP serves a KEP by its SIG and number, such as
+// /api/v1/keps/sig-node/14, or by its path below the keps directory.
+func (s *server) handleAPIKEP(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, "GET", "HEAD") {
+		return
+	}
+	ref := strings.TrimPrefix(r.URL.Path, apiPrefix+"/keps/")
+	p := s.lookupNumber(ref)
+	if p == nil {
+		p = s.lookup(strings.TrimSuffix(ref, ".md"))
+	}
+	if p == nil {
+		writeJSONError(w, http.StatusNotFound, errors.Errorf("no KEP %q", ref))
+		return
+	}
+	writeJSON(w, r, http.StatusOK, s.summarise(p))
+}
+
+// lookupNumber finds the KEP a SIG/number reference such as sig-node/14 or
+// node/KEP-14 points to.
+func (s *server) lookupNumber(ref string) *keps.Proposal {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 {
+		return nil
+	}
+	m := requestNumberRe.FindStringSubmatch(parts[1])
+	if m == nil {
+		return nil
+	}
+	sig := s.rules.CanonicalSIG(parts[0])
+	for _, p := range s.snapshot() {
+		if s.number(p) == m[1] && owningSIG(p, s.rules) == sig {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *server) handleAPISIGs(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, "GET", "HEAD") {
+		return
+	}
+	writeJSON(w, r, http.StatusOK, aggregateSIGs(s.snapshot(), s.rules, s.now(), validations.StaleAfter))
+}
+
+func (s *server) handleAPIPeople(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, "GET", "HEAD") {
+		return
+	}
+	writeJSON(w, r, http.StatusOK, s.people())
+}
+
+// handleAPIValidate validates the KEP in the request body with the server's
+// rules. The sections and toc query parameters turn on the checks of the
+// same name in kepval.
+func (s *server) handleAPIValidate(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, "POST") {
+		return
+	}
+	// Reading one byte past the limit tells a KEP that is too large apart
+	// from a request that failed.
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(io.LimitReader(r.Body, maxKEPSize+1)); err != nil {
+		writeJSONError(w, http.StatusBadRequest, errors.Wrap(err, "error reading the KEP"))
+		return
+	}
+	if body.Len() > maxKEPSize {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, errors.Errorf("the KEP must be at most %d bytes", maxKEPSize))
+		return
+	}
+	var checks []keps.Check
+	for _, c := range []struct {
+		name  string
+		check keps.Check
+	}{
+		{"sections", func(p *keps.Proposal) error { return p.ValidateSections(s.rules) }},
+		{"toc", (*keps.Proposal).ValidateTableOfContents},
+	} {
+		if on, _ := strconv.ParseBool(r.URL.Query().Get(c.name)); on {
+			checks = append(checks, c.check)
+		}
+	}
+	kep := (&keps.Parser{Config: s.rules}).Parse(&body)
+	err := kep.Validate(checks, s.rules)
+	errs, ok := err.(validations.ErrorList)
+	writeJSON(w, r, http.StatusOK, &validation{
+		Valid:    err == nil || ok && !errs.HasAtLeast(validations.SeverityError),
+		Problems: problems(err),
+	})
+}
+
+// handleAPIEvents streams a "change" server-sent event for every KEP that
+// changes while serve -watch is running.
+func (s *server) handleAPIEvents(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, "GET") {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+	changes := s.subscribe()
+	defer s.unsubscribe(changes)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case batch := <-changes:
+			for _, c := range batch {
+				data, err := json.Marshal(c)
+				if err != nil {
+					return
+				}
+				fmt.Fprintf(w, "event: change\ndata: %s\n\n", data)
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *server) handleAPIOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, "GET", "HEAD") {
+		return
+	}
+	writeJSON(w, r, http.StatusOK, json.RawMessage(openAPI))
+}
+
+// allowMethods responds with 405 Method Not Allowed unless the request uses
+// one of methods.
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeJSONError(w, http.StatusMethodNotAllowed, errors.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+// writeJSON writes v as JSON. Successful responses to GET and HEAD have an
+// ETag, and are 304 Not Modified when it matches If-None-Match.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	body, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body = append(body, '\n')
+	w.Header().Set("Content-Type", "application/json")
+	if status == http.StatusOK && (r.Method == "GET" || r.Method == "HEAD") {
+		sum := sha256.Sum256(body)
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.WriteHeader(status)
+	if r.Method != "HEAD" {
+		w.Write(body)
+	}
+}
+
+// etagMatches returns true if an If-None-Match header lists etag. Weak
+// validators match their strong equivalent.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	body, _ := json.MarshalIndent(map[string]string{"error": err.Error()}, "", "  ")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(body, '\n'))
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestAPIKEPs(t *testing.T) {
+	s, cleanup := testServer(t)
+	defer cleanup()
+	h := s.routes()
+
+	var page kepPage
+	rec := get(t, h, "/api/v1/keps?sort=created&order=desc&limit=1")
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 2 || len(page.KEPs) != 1 || page.KEPs[0].ID != "sig-node/0001-cpu" || page.KEPs[0].Number != "1" {
+		t.Fatalf("unexpected page %+v", page)
+	}
+	next := rec.Header().Get("Link")
+	if next != `</api/v1/keps?limit=1&offset=1&order=desc&sort=created>; rel="next"` {
+		t.Fatalf("unexpected Link header %q", next)
+	}
+
+	rec = get(t, h, "/api/v1/keps?limit=1&offset=1&order=desc&sort=created")
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.KEPs) != 1 || page.KEPs[0].SIG != "sig-network" || rec.Header().Get("Link") != "" {
+		t.Fatalf("unexpected last page %+v", page)
+	}
+
+	for _, url := range []string{"/api/v1/keps?limit=0", "/api/v1/keps?offset=-1", "/api/v1/keps?sort=bogus"} {
+		if rec := get(t, h, url); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected %s to be a bad request but got %d", url, rec.Code)
+		}
+	}
+}
+
+func TestAPIKEP(t *testing.T) {
+	s, cleanup := testServer(t)
+	defer cleanup()
+	h := s.routes()
+	testcases := []struct {
+		url    string
+		status int
+		title  string
+	}{
+		{"/api/v1/keps/sig-network/2", http.StatusOK, "DNS"},
+		{"/api/v1/keps/network/KEP-0002", http.StatusOK, "DNS"},
+		{"/api/v1/keps/sig-network/0002-dns", http.StatusOK, "DNS"},
+		{"/api/v1/keps/sig-node/2", http.StatusNotFound, ""},
+		{"/api/v1/keps/sig-network/missing", http.StatusNotFound, ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.url, func(t *testing.T) {
+			rec := get(t, h, tc.url)
+			if rec.Code != tc.status {
+				t.Fatalf("expected status %d but got %d: %s", tc.status, rec.Code, rec.Body)
+			}
+			var summary kepSummary
+			if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+				t.Fatal(err)
+			}
+			if summary.Title != tc.title {
+				t.Fatalf("expected %q but got %+v", tc.title, summary)
+			}
+		})
+	}
+}
+
+func TestAPIETag(t *testing.T) {
+	s, cleanup := testServer(t)
+	defer cleanup()
+	h := s.routes()
+	for _, url := range []string{"/api/v1/keps", "/api/v1/sigs", "/api/v1/people", "/api/v1/openapi.json"} {
+		t.Run(url, func(t *testing.T) {
+			rec := get(t, h, url)
+			etag := rec.Header().Get("ETag")
+			if rec.Code != http.StatusOK || etag == "" {
+				t.Fatalf("expected a 200 with an ETag but got %d %q", rec.Code, etag)
+			}
+			for _, header := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+				req := httptest.NewRequest("GET", url, nil)
+				req.Header.Set("If-None-Match", header)
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+					t.Fatalf("expected If-None-Match %s to be not modified but got %d", header, rec.Code)
+				}
+			}
+			req := httptest.NewRequest("GET", url, nil)
+			req.Header.Set("If-None-Match", `"other"`)
+			rec = httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected a stale ETag to get a 200 but got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestAPIValidate(t *testing.T) {
+	s, cleanup := testServer(t)
+	defer cleanup()
+	h := s.routes()
+	testcases := []struct {
+		name  string
+		url   string
+		kep   string
+		valid bool
+		codes []string
+	}{
+		{
+			"valid with warnings",
+			"/api/v1/validate",
+			"---\ntitle: T\nauthors:\n  - \"@a\"\nowning-sig: sig-node\nreviewers:\n  - \"@b\"\napprovers:\n  - \"@c\"\neditor: \"@d\"\ncreation-date: 2019-01-01\nlast-updated: 2019-05-01\nstatus: provisional\n---\n",
+			true,
+			nil,
+		},
+		{
+			"invalid metadata",
+			"/api/v1/validate",
+			"---\ntitle: T\nauthors: someone\n---\n",
+			false,
+			[]string{"value-must-be-list-of-strings"},
+		},
+		{
+			"sections",
+			"/api/v1/validate?sections=true",
+			"---\ntitle: T\nauthors:\n  - \"@a\"\nowning-sig: sig-node\nreviewers:\n  - \"@b\"\napprovers:\n  - \"@c\"\neditor: \"@d\"\ncreation-date: 2019-01-01\nlast-updated: 2019-05-01\nstatus: provisional\n---\n",
+			false,
+			[]string{"missing-section"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("POST", tc.url, strings.NewReader(tc.kep)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body)
+			}
+			var result validation
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatal(err)
+			}
+			if result.Valid != tc.valid {
+				t.Fatalf("expected valid to be %v but got %+v", tc.valid, result)
+			}
+			for _, code := range tc.codes {
+				found := false
+				for _, p := range result.Problems {
+					found = found || p.Code == code
+				}
+				if !found {
+					t.Fatalf("expected a %s problem but got %+v", code, result.Problems)
+				}
+			}
+		})
+	}
+
+	rec := get(t, h, "/api/v1/validate")
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "POST" {
+		t.Fatalf("expected GET to be not allowed but got %d", rec.Code)
+	}
+}
+
+// failingReader fails partway through a request body.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+func TestAPIValidateBody(t *testing.T) {
+	s, cleanup := testServer(t)
+	defer cleanup()
+	h := s.routes()
+	testcases := []struct {
+		name   string
+		body   io.Reader
+		status int
+	}{
+		{"at the limit", strings.NewReader(strings.Repeat("x", maxKEPSize)), http.StatusOK},
+		{"too large", strings.NewReader(strings.Repeat("x", maxKEPSize+1)), http.StatusRequestEntityTooLarge},
+		{"failed read", failingReader{}, http.StatusBadRequest},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/validate", tc.body))
+			if rec.Code != tc.status {
+				t.Fatalf("expected status %d but got %d: %s", tc.status, rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	var doc struct {
+		Paths map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(openAPI), &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/keps", "/keps/{sig}/{number}", "/sigs", "/people", "/validate", "/events"} {
+		if _, ok := doc.Paths[path]; !ok {
+			t.Errorf("the OpenAPI document doesn't describe %s", path)
+		}
+	}
+}
+
+func TestAPIEvents(t *testing.T) {
+	s, cleanup := testServer(t)
+	defer cleanup()
+	srv := httptest.NewServer(s.routes())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/v1/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+	s.publish([]change{{Filename: "0001-a.md", Kind: changeAdded, New: []problem{}, Fixed: []problem{}}})
+
+	lines := bufio.NewScanner(resp.Body)
+	var got []string
+	for len(got) < 2 && lines.Scan() {
+		got = append(got, lines.Text())
+	}
+	if len(got) != 2 || got[0] != "event: change" || !strings.Contains(got[1], `"filename":"0001-a.md"`) {
+		t.Fatalf("unexpected event %q", got)
+	}
+}
@@ -20,194 +20,140 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
 	"github.com/pkg/errors"
 )
 
 type config struct {
-	root      string
-	debug     bool
-	sortField string
+	root       string
+	debug      bool
+	sortField  string
+	configPath string
+	sigsPath   string
+	groupBy    string
 }
 
-func main() {
-	configuration := &config{}
-	list := flag.NewFlagSet("list", flag.ExitOnError)
-	list.StringVar(&configuration.root, "keps", ".", "the location of the keps directory")
-	list.BoolVar(&configuration.debug, "debug", false, "see debug logs")
-	list.StringVar(&configuration.root, "root", "", "the root of the keps dir (enhancements/keps)")
-	list.Parse(os.Args[1:])
-
-	ef := NewEnhancementFinder(
-		WithLog(&Logger{configuration.debug}),
-	)
+// commands are the kepview subcommands. list runs when none is given.
+var commands = map[string]func(args []string) error{
+	"list":   list,
+	"people": people,
+	"serve":  serve,
+	"sigs":   sigs,
+	"stale":  stale,
+	"watch":  watch,
+}
 
-	out := &keps.Proposals{}
-	if err := filepath.Walk(configuration.root, ef.Find(out)); err != nil {
-		fmt.Printf("%+v", err)
-		os.Exit(2)
+func main() {
+	name, args := "list", os.Args[1:]
+	if len(args) > 0 {
+		if _, ok := commands[args[0]]; ok {
+			name, args = args[0], args[1:]
+		}
 	}
-	jsonOut, err := json.Marshal(out)
-	if err != nil {
+	if err := commands[name](args); err != nil {
 		fmt.Printf("%+v", err)
 		os.Exit(2)
 	}
-	fmt.Println(string(jsonOut))
 }
 
-type Logger struct {
-	debug bool
+// flags returns a flag set with the flags every subcommand shares.
+func (c *config) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&c.root, "keps", ".", "the location of the keps directory")
+	fs.BoolVar(&c.debug, "debug", false, "see debug logs")
+	fs.StringVar(&c.configPath, "config", "", "the project configuration file (defaults to the nearest "+validations.ConfigFilename+")")
+	fs.StringVar(&c.sigsPath, "sigs", "", "a kubernetes/community sigs.yaml listing the SIGs KEPs may name")
+	return fs
 }
 
-func (l *Logger) Debugf(format string, args ...interface{}) {
-	if l.debug {
-		fmt.Printf(format, args...)
+// proposals parses every KEP under the keps directory.
+func (c *config) proposals() (keps.Proposals, *validations.Config, error) {
+	rules, err := loadRules(c)
+	if err != nil {
+		return nil, nil, err
 	}
-}
-
-type parser interface {
-	Parse(io.Reader) *keps.Proposal
-}
-
-type opener interface {
-	Open(string) (*os.File, error)
-}
-
-type Opener struct{}
-
-func (o *Opener) Open(path string) (*os.File, error) {
-	return os.Open(path)
-}
-
-type logger interface {
-	Debugf(format string, args ...interface{})
-}
-
-func defaultFilters() []filter {
-	return []filter{
-		filenameFilter{
-			func(in string) bool {
-				return strings.HasPrefix(in, "README")
-			},
-			"Ignore READMEs",
-		},
-		filenameFilter{
-			func(in string) bool {
-				return !strings.HasSuffix(in, ".md")
-			},
-			"Ignore non markdown files",
-		},
-		filenameFilter{
-			func(in string) bool {
-				return strings.HasSuffix(in, "template.md")
-			},
-			"Ignore template files",
-		},
-		filenameFilter{
-			func(in string) bool {
-				return in == "kep-faq.md"
-			},
-			"Ignore the kep faq",
-		},
-		filenameFilter{
-			func(in string) bool {
-				return in == "0023-documentation-for-images.md"
-			},
-			"Ignore the non-kep file",
-		},
+	out := &keps.Proposals{}
+	if err := filepath.Walk(c.root, c.finder(rules).Find(out)); err != nil {
+		return nil, nil, err
 	}
+	return *out, rules, nil
 }
 
-type filter interface {
-	Filter(string) bool
-}
-type filenameFilter struct {
-	f    func(string) bool
-	name string
+// finder returns an EnhancementFinder that parses KEPs with rules.
+func (c *config) finder(rules *validations.Config) *keps.EnhancementFinder {
+	return keps.NewEnhancementFinder(
+		keps.WithLog(&Logger{c.debug}),
+		keps.WithParser(&keps.Parser{Config: rules}),
+	)
 }
 
-func (f filenameFilter) Filter(in string) bool {
-	return f.f(in)
-}
-func (f filenameFilter) String() string {
-	return f.name
-}
+// list prints every KEP as JSON.
+func list(args []string) error {
+	configuration := &config{}
+	fs := configuration.flags("list")
+	fs.StringVar(&configuration.groupBy, "group-by", "", "group the KEPs by \"sig\", their canonical owning SIG")
+	fs.Parse(args)
 
-// EnhancementFinder can filter out non-enhancement-like filenames in
-// addition to parsing the KEPs and reporting failure statuses
-type EnhancementFinder struct {
-	opener          opener
-	parser          parser
-	filenameFilters []filter
-	log             logger
-}
+	if configuration.groupBy != "" && configuration.groupBy != "sig" {
+		return errors.Errorf("unknown -group-by %q, must be sig", configuration.groupBy)
+	}
 
-// NewEnhancementFinder returns a reasonably configured EnhancementFinder
-func NewEnhancementFinder(opts ...finderOpts) *EnhancementFinder {
-	ef := &EnhancementFinder{
-		opener:          &Opener{},
-		parser:          &keps.Parser{},
-		log:             &Logger{},
-		filenameFilters: defaultFilters(),
+	out, rules, err := configuration.proposals()
+	if err != nil {
+		return err
 	}
-	for _, opt := range opts {
-		opt(ef)
+	var result interface{} = out
+	if configuration.groupBy == "sig" {
+		result = groupBySIG(out, rules)
 	}
-	return ef
-}
-
-type finderOpts func(*EnhancementFinder)
-
-// WithOpener sets the object that opens files
-func WithOpener(opener opener) finderOpts {
-	return func(e *EnhancementFinder) { e.opener = opener }
+	jsonOut, err := json.Marshal(result)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Println(string(jsonOut))
+	return nil
 }
 
-// WithParser sets the parser that prases KEPs
-func WithParser(parser parser) finderOpts {
-	return func(e *EnhancementFinder) { e.parser = parser }
+// loadRules reads the validation rules from the configured file or the one
+// nearest the keps directory, falling back to the default rules.
+func loadRules(c *config) (*validations.Config, error) {
+	rules, err := validations.LoadConfig(c.configPath, c.root)
+	if err != nil {
+		return nil, err
+	}
+	if c.sigsPath != "" {
+		sigs, err := validations.LoadSIGRegistry(c.sigsPath)
+		if err != nil {
+			return nil, err
+		}
+		rules.SetSIGRegistry(sigs)
+	}
+	return rules, nil
 }
 
-// WithLog defines the logger for the finder
-func WithLog(log logger) finderOpts {
-	return func(e *EnhancementFinder) { e.log = log }
+// groupBySIG groups KEPs by the canonical name of their owning SIG.
+func groupBySIG(proposals keps.Proposals, rules *validations.Config) map[string]keps.Proposals {
+	groups := map[string]keps.Proposals{}
+	for _, p := range proposals {
+		sig := rules.CanonicalSIG(p.OwningSIG)
+		if sig == "" {
+			sig = "unknown"
+		}
+		groups[sig] = append(groups[sig], p)
+	}
+	return groups
 }
 
-// WithFilenameFilters sets the list of filters the filenames must pass
-func WithFilenameFilters(filters ...filter) finderOpts {
-	return func(e *EnhancementFinder) { e.filenameFilters = filters }
+type Logger struct {
+	debug bool
 }
 
-// Find returns a function that filters out filenames and prases a valid KEP file.
-// Is also a WalkFunc.
-func (e *EnhancementFinder) Find(out *keps.Proposals) filepath.WalkFunc {
-	return func(path string, info os.FileInfo, err error) error {
-		if path == "" {
-			return nil
-		}
-		if err != nil {
-			return errors.WithStack(err)
-		}
-		for _, f := range e.filenameFilters {
-			if f.Filter(info.Name()) {
-				e.log.Debugf("Skipping %q due to filename filter: %v\n", info.Name(), f)
-				return nil
-			}
-		}
-		file, err := e.opener.Open(path)
-		if err != nil {
-			return errors.Wrapf(err, "filename: %v", info.Name())
-		}
-		defer file.Close()
-		// Parse always returns a proposal even on failure.
-		kep := e.parser.Parse(file)
-		kep.Filename = path
-		out.AddProposal(kep)
-		return nil
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	if l.debug {
+		fmt.Printf(format, args...)
 	}
 }
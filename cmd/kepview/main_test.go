@@ -17,118 +17,42 @@ limitations under the License.
 package main
 
 import (
-	"io"
-	"os"
 	"testing"
-	"time"
 
 	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
 )
 
-type info struct {
-	name string
-}
-
-func (i *info) Name() string       { return i.name }
-func (i *info) Size() int64        { return 0 }
-func (i *info) Mode() os.FileMode  { return os.FileMode(100) }
-func (i *info) ModTime() time.Time { return time.Date(2019, 4, 20, 0, 0, 0, 0, nil) }
-func (i *info) IsDir() bool        { return false }
-func (i *info) Sys() interface{}   { return struct{}{} }
-
-type myparser struct {
-	proposal *keps.Proposal
-}
-
-func (p *myparser) Parse(reader io.Reader) *keps.Proposal {
-	return p.proposal
-}
-
-type myopener struct {
-	file *os.File
-}
-
-func (o *myopener) Open(path string) (*os.File, error) {
-	return o.file, nil
-}
-
-type mylogger struct{}
-
-func (l *mylogger) Debugf(format string, args ...interface{}) {}
-
-func defaultTestEnhancementFinder(...finderOpts) *EnhancementFinder {
-	return &EnhancementFinder{
-		opener:          &myopener{},
-		parser:          &myparser{},
-		log:             &mylogger{},
-		filenameFilters: defaultFilters(),
+func TestGroupBySIG(t *testing.T) {
+	rules := validations.DefaultConfig()
+	rules.SetSIGRegistry(validations.NewSIGRegistry("sig-network", "sig-node"))
+	proposals := keps.Proposals{
+		{Title: "a", OwningSIG: "sig-network"},
+		{Title: "b", OwningSIG: "SIG Network"},
+		{Title: "c", OwningSIG: "node"},
+		{Title: "d"},
 	}
-}
-
-func TestFindEnhancementsIgnores(t *testing.T) {
-	testcases := []struct {
-		name     string
-		filename string
-	}{
-		{
-			"a basic readme",
-			"README.md",
-		},
-		{
-			"owners file",
-			"OWNERS",
-		},
-		{
-			"images",
-			"something.png",
-		},
-		{
-			"ignore templates",
-			"myfavorite-template.md",
-		},
-	}
-	for _, tc := range testcases {
-		t.Run(tc.name, func(t *testing.T) {
-			ef := defaultTestEnhancementFinder()
-			out := &keps.Proposals{}
-			fe := ef.Find(out)
-			i := &info{tc.filename}
-			if err := fe("test", i, nil); err != nil {
-				t.Fatalf("%+v", err)
-			}
-			if len(*out) != 0 {
-				t.Fatalf("Did not expect to find anything but found %v", out)
-			}
-		})
+	groups := groupBySIG(proposals, rules)
+	if len(groups["sig-network"]) != 2 || len(groups["sig-node"]) != 1 || len(groups["unknown"]) != 1 {
+		t.Fatalf("unexpected groups %v", groups)
 	}
 }
 
-func TestEnhancementFinder(t *testing.T) {
+func TestFlagsDefaultToCurrentDirectory(t *testing.T) {
 	testcases := []struct {
-		name     string
-		filename string
+		args     []string
+		expected string
 	}{
-		{
-			"simple test",
-			"my-simple-test.md",
-		},
+		{nil, "."},
+		{[]string{"-keps", "enhancements/keps"}, "enhancements/keps"},
 	}
 	for _, tc := range testcases {
-		t.Run(tc.name, func(t *testing.T) {
-			ef := defaultTestEnhancementFinder()
-			ef.parser = &myparser{&keps.Proposal{}}
-			out := &keps.Proposals{}
-			fe := ef.Find(out)
-			i := &info{tc.filename}
-			if err := fe("test", i, nil); err != nil {
-				t.Fatalf("%+v", err)
-			}
-			if len(*out) != 1 {
-				t.Fatalf("Expected 1 item but found: %v", out)
-			}
-			if (*out)[0].Filename != "test" {
-				t.Fatalf("expected proposal to have a filename of %q but had %q", tc.filename, (*out)[0].Filename)
-			}
-		})
+		c := &config{}
+		if err := c.flags("test").Parse(tc.args); err != nil {
+			t.Fatal(err)
+		}
+		if c.root != tc.expected {
+			t.Fatalf("expected the keps directory %q for %v but got %q", tc.expected, tc.args, c.root)
+		}
 	}
 }
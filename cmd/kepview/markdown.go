@@ -0,0 +1,245 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/chuckha/kepview/keps"
+)
+
+// The markdown KEPs use: headings, paragraphs, lists, block quotes, tables,
+// code and rules, with links, images, code spans and emphasis inline. HTML in
+// the source is escaped rather than rendered.
+var (
+	fenceRe      = regexp.MustCompile("^\\s*(```+|~~~+)")
+	headingRe    = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listItemRe   = regexp.MustCompile(`^(\s*)([-*+]|[0-9]+[.)])\s+(.*)$`)
+	ruleRe       = regexp.MustCompile(`^\s{0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	tableSepRe   = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	commentRe    = regexp.MustCompile(`(?s)<!--.*?-->`)
+	imageRe      = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?([^)\s>]*)>?(?:\s+"[^"]*")?\)`)
+	linkRe       = regexp.MustCompile(`\[([^\]]+)\]\(\s*<?([^)\s>]*)>?(?:\s+"[^"]*")?\)`)
+	autolinkRe   = regexp.MustCompile(`&lt;(https?://[^\s&]+)&gt;`)
+	boldRe       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicRe     = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*]*)\*|(^|[^\w_])_([^_\s][^_]*)_`)
+	codeSpanSrc  = regexp.MustCompile("`+([^`]+)`+")
+	safeSchemeRe = regexp.MustCompile(`^(?i:https?:|mailto:|#|/|\.|[^:]*$)`)
+)
+
+// renderMarkdown renders a KEP body as HTML.
+func renderMarkdown(body string) template.HTML {
+	r := &renderer{}
+	lines := strings.Split(strings.Replace(commentRe.ReplaceAllString(body, ""), "\r\n", "\n", -1), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fenceRe.MatchString(line):
+			r.closeBlocks()
+			fence := fenceRe.FindStringSubmatch(line)[1]
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			r.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+		case trimmed == "":
+			r.closeParagraph()
+			r.blank = true
+		case headingRe.MatchString(line):
+			r.closeBlocks()
+			m := headingRe.FindStringSubmatch(line)
+			level := len(m[1])
+			fmt.Fprintf(r, "<h%d id=\"%s\">%s</h%d>\n", level, html.EscapeString(keps.Slug(m[2])), inline(m[2]), level)
+		case ruleRe.MatchString(line):
+			r.closeBlocks()
+			r.WriteString("<hr>\n")
+		case strings.HasPrefix(trimmed, ">"):
+			r.closeLists()
+			r.closeParagraph()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quote = append(quote, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+			i--
+			r.WriteString("<blockquote>\n" + string(renderMarkdown(strings.Join(quote, "\n"))) + "</blockquote>\n")
+		case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && tableSepRe.MatchString(lines[i+1]):
+			r.closeBlocks()
+			r.WriteString("<table>\n<thead><tr>")
+			for _, cell := range tableCells(line) {
+				r.WriteString("<th>" + inline(cell) + "</th>")
+			}
+			r.WriteString("</tr></thead>\n<tbody>\n")
+			for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				r.WriteString("<tr>")
+				for _, cell := range tableCells(lines[i]) {
+					r.WriteString("<td>" + inline(cell) + "</td>")
+				}
+				r.WriteString("</tr>\n")
+			}
+			i--
+			r.WriteString("</tbody>\n</table>\n")
+		case listItemRe.MatchString(line):
+			r.closeParagraph()
+			m := listItemRe.FindStringSubmatch(line)
+			r.listItem(len(strings.Replace(m[1], "\t", "    ", -1)), m[2], m[3])
+		default:
+			if len(r.lists) > 0 && !r.blank {
+				// a continuation of the current list item
+				r.WriteString(" " + inline(trimmed))
+				continue
+			}
+			r.closeLists()
+			if !r.paragraph {
+				r.WriteString("<p>")
+				r.paragraph = true
+			} else {
+				r.WriteString("\n")
+			}
+			r.WriteString(inline(trimmed))
+		}
+		if trimmed != "" {
+			r.blank = false
+		}
+	}
+	r.closeBlocks()
+	return template.HTML(r.String())
+}
+
+type mdList struct {
+	indent  int
+	ordered bool
+}
+
+type renderer struct {
+	strings.Builder
+	lists     []mdList
+	paragraph bool
+	blank     bool
+}
+
+func (r *renderer) listItem(indent int, marker, text string) {
+	ordered := marker != "-" && marker != "*" && marker != "+"
+	for len(r.lists) > 0 && indent < r.lists[len(r.lists)-1].indent {
+		r.closeList()
+	}
+	if len(r.lists) > 0 && indent == r.lists[len(r.lists)-1].indent && ordered != r.lists[len(r.lists)-1].ordered {
+		r.closeList()
+	}
+	if len(r.lists) == 0 || indent > r.lists[len(r.lists)-1].indent {
+		r.lists = append(r.lists, mdList{indent, ordered})
+		if ordered {
+			r.WriteString("<ol>\n<li>")
+		} else {
+			r.WriteString("<ul>\n<li>")
+		}
+	} else {
+		r.WriteString("</li>\n<li>")
+	}
+	r.WriteString(inline(text))
+}
+
+func (r *renderer) closeList() {
+	l := r.lists[len(r.lists)-1]
+	r.lists = r.lists[:len(r.lists)-1]
+	if l.ordered {
+		r.WriteString("</li>\n</ol>\n")
+	} else {
+		r.WriteString("</li>\n</ul>\n")
+	}
+}
+
+func (r *renderer) closeLists() {
+	for len(r.lists) > 0 {
+		r.closeList()
+	}
+}
+
+func (r *renderer) closeParagraph() {
+	if r.paragraph {
+		r.WriteString("</p>\n")
+		r.paragraph = false
+	}
+}
+
+func (r *renderer) closeBlocks() {
+	r.closeParagraph()
+	r.closeLists()
+}
+
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(line, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// inline renders the inline markdown in text, escaping everything else.
+func inline(text string) string {
+	// Code spans, images and links are rendered first and replaced with
+	// placeholders, so emphasis only applies to the text between them and
+	// never to a URL.
+	var spans []string
+	hold := func(span string) string {
+		spans = append(spans, span)
+		return fmt.Sprintf("\x00%d\x00", len(spans)-1)
+	}
+	text = codeSpanSrc.ReplaceAllStringFunc(text, func(s string) string {
+		return hold("<code>" + html.EscapeString(strings.TrimSpace(codeSpanSrc.FindStringSubmatch(s)[1])) + "</code>")
+	})
+	text = html.EscapeString(text)
+	text = imageRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := imageRe.FindStringSubmatch(s)
+		return hold(fmt.Sprintf(`<img src="%s" alt="%s">`, safeURL(m[2]), m[1]))
+	})
+	text = linkRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := linkRe.FindStringSubmatch(s)
+		return hold(fmt.Sprintf(`<a href="%s">%s</a>`, safeURL(m[2]), emphasis(m[1])))
+	})
+	text = autolinkRe.ReplaceAllStringFunc(text, func(s string) string {
+		return hold(autolinkRe.ReplaceAllString(s, `<a href="$1">$1</a>`))
+	})
+	text = emphasis(text)
+	// Links can hold code spans, so later placeholders are restored first.
+	for i := len(spans) - 1; i >= 0; i-- {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), spans[i], 1)
+	}
+	return text
+}
+
+// emphasis renders bold and italic text.
+func emphasis(text string) string {
+	text = boldRe.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = italicRe.ReplaceAllString(text, "$1<em>$2</em>$3<em>$4</em>")
+	return strings.Replace(text, "<em></em>", "", -1)
+}
+
+// safeURL drops link targets with schemes such as javascript:. The target is
+// already HTML escaped.
+func safeURL(u string) string {
+	if !safeSchemeRe.MatchString(html.UnescapeString(u)) {
+		return "#"
+	}
+	return u
+}
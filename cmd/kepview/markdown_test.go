@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	testcases := []struct {
+		name     string
+		markdown string
+		contains []string
+		excludes []string
+	}{
+		{
+			"headings get anchors",
+			"## Design Details\n",
+			[]string{`<h2 id="design-details">Design Details</h2>`},
+			nil,
+		},
+		{
+			"paragraphs and emphasis",
+			"Some **bold** and *italic* text\nwith `a <code> span`.\n\nAnother paragraph.\n",
+			[]string{"<p>Some <strong>bold</strong> and <em>italic</em> text\nwith <code>a &lt;code&gt; span</code>.</p>", "<p>Another paragraph.</p>"},
+			nil,
+		},
+		{
+			"nested lists",
+			"- one\n  - nested\n- two\n\n1. first\n",
+			[]string{"<ul>\n<li>one<ul>\n<li>nested</li>\n</ul>\n</li>\n<li>two</li>\n</ul>", "<ol>\n<li>first</li>\n</ol>"},
+			nil,
+		},
+		{
+			"fenced code is escaped",
+			"```go\nif a < b {\n```\n",
+			[]string{"<pre><code>if a &lt; b {</code></pre>"},
+			nil,
+		},
+		{
+			"tables",
+			"| a | b |\n|---|---|\n| 1 | 2 |\n",
+			[]string{"<th>a</th><th>b</th>", "<td>1</td><td>2</td>"},
+			nil,
+		},
+		{
+			"links and images",
+			"[other](./0002-other.md) ![diagram](images/d.png)\n",
+			[]string{`<a href="./0002-other.md">other</a>`, `<img src="images/d.png" alt="diagram">`},
+			nil,
+		},
+		{
+			"emphasis leaves urls alone",
+			"See [the *new* design](https://example.com/a_b_c/x*y*z.md) and _this_ ![a_b](img/my_diagram_v2.png) <https://example.com/__init__.py>\n",
+			[]string{
+				`<a href="https://example.com/a_b_c/x*y*z.md">the <em>new</em> design</a>`,
+				"<em>this</em>",
+				`<img src="img/my_diagram_v2.png" alt="a_b">`,
+				`<a href="https://example.com/__init__.py">https://example.com/__init__.py</a>`,
+			},
+			[]string{"<em>b</em>", "<strong>init</strong>"},
+		},
+		{
+			"code spans in links",
+			"[`a_b_c`](docs/a_b_c.md)\n",
+			[]string{`<a href="docs/a_b_c.md"><code>a_b_c</code></a>`},
+			nil,
+		},
+		{
+			"raw html, comments and unsafe links",
+			"<script>alert(1)</script>\n<!-- a comment -->\n[x](javascript:alert(1))\n",
+			[]string{"&lt;script&gt;", `<a href="#">x</a>`},
+			[]string{"<script>", "a comment", "javascript:"},
+		},
+		{
+			"block quotes",
+			"> quoted\n",
+			[]string{"<blockquote>\n<p>quoted</p>\n</blockquote>"},
+			nil,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := string(renderMarkdown(tc.markdown))
+			for _, want := range tc.contains {
+				if !strings.Contains(out, want) {
+					t.Errorf("expected %q in:\n%s", want, out)
+				}
+			}
+			for _, unwanted := range tc.excludes {
+				if strings.Contains(out, unwanted) {
+					t.Errorf("did not expect %q in:\n%s", unwanted, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+// openAPI describes the JSON API kepview serve exposes under /api/v1.
+const openAPI = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "kepview",
+    "description": "Read-only access to Kubernetes Enhancement Proposals and their validation results.",
+    "version": "v1"
+  },
+  "servers": [{"url": "/api/v1"}],
+  "paths": {
+    "/keps": {
+      "get": {
+        "summary": "List KEPs matching a query",
+        "parameters": [
+          {"name": "sig", "in": "query", "description": "Only KEPs owned by this SIG.", "schema": {"type": "string"}},
+          {"name": "status", "in": "query", "description": "Only KEPs with this status.", "schema": {"type": "string"}},
+          {"name": "q", "in": "query", "description": "Only KEPs whose title or path contains this text.", "schema": {"type": "string"}},
+          {"name": "sort", "in": "query", "schema": {"type": "string", "enum": ["title", "sig", "status", "created", "updated"], "default": "title"}},
+          {"name": "order", "in": "query", "schema": {"type": "string", "enum": ["asc", "desc"], "default": "asc"}},
+          {"name": "offset", "in": "query", "schema": {"type": "integer", "minimum": 0, "default": 0}},
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 500, "default": 50}},
+          {"$ref": "#/components/parameters/IfNoneMatch"}
+        ],
+        "responses": {
+          "200": {
+            "description": "A page of KEPs. The Link header points to the next page, if any.",
+            "headers": {"ETag": {"$ref": "#/components/headers/ETag"}, "Link": {"schema": {"type": "string"}}},
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/KEPPage"}}}
+          },
+          "304": {"$ref": "#/components/responses/NotModified"},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/keps/{sig}/{number}": {
+      "get": {
+        "summary": "Get a KEP by its owning SIG and number",
+        "parameters": [
+          {"name": "sig", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "number", "in": "path", "required": true, "description": "The KEP number, such as 14, 0014 or KEP-14.", "schema": {"type": "string"}},
+          {"$ref": "#/components/parameters/IfNoneMatch"}
+        ],
+        "responses": {
+          "200": {
+            "description": "The KEP.",
+            "headers": {"ETag": {"$ref": "#/components/headers/ETag"}},
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/KEP"}}}
+          },
+          "304": {"$ref": "#/components/responses/NotModified"},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/sigs": {
+      "get": {
+        "summary": "Summarise each SIG's KEPs",
+        "parameters": [{"$ref": "#/components/parameters/IfNoneMatch"}],
+        "responses": {
+          "200": {
+            "description": "The SIGs, by name.",
+            "headers": {"ETag": {"$ref": "#/components/headers/ETag"}},
+            "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/SIG"}}}}
+          },
+          "304": {"$ref": "#/components/responses/NotModified"}
+        }
+      }
+    },
+    "/people": {
+      "get": {
+        "summary": "Show the KEPs each person is authoring, reviewing and approving",
+        "parameters": [{"$ref": "#/components/parameters/IfNoneMatch"}],
+        "responses": {
+          "200": {
+            "description": "Everyone on a KEP, busiest first.",
+            "headers": {"ETag": {"$ref": "#/components/headers/ETag"}},
+            "content": {"application/json": {"schema": {"type": "object"}}}
+          },
+          "304": {"$ref": "#/components/responses/NotModified"}
+        }
+      }
+    },
+    "/events": {
+      "get": {
+        "summary": "Stream changes to KEPs while serve -watch is running",
+        "responses": {
+          "200": {
+            "description": "A server-sent event named change for every KEP that is added, changed or removed.",
+            "content": {"text/event-stream": {"schema": {"$ref": "#/components/schemas/Change"}}}
+          }
+        }
+      }
+    },
+    "/validate": {
+      "post": {
+        "summary": "Validate a KEP",
+        "parameters": [
+          {"name": "sections", "in": "query", "description": "Check the KEP has the sections its status requires.", "schema": {"type": "boolean"}},
+          {"name": "toc", "in": "query", "description": "Check the table of contents matches the headings.", "schema": {"type": "boolean"}}
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {"text/markdown": {"schema": {"type": "string", "maxLength": 1048576}}}
+        },
+        "responses": {
+          "200": {
+            "description": "The problems found in the KEP.",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Validation"}}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "413": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "IfNoneMatch": {"name": "If-None-Match", "in": "header", "description": "An ETag from an earlier response.", "schema": {"type": "string"}}
+    },
+    "headers": {
+      "ETag": {"description": "Identifies this version of the response.", "schema": {"type": "string"}}
+    },
+    "responses": {
+      "NotModified": {"description": "The response hasn't changed since the ETag in If-None-Match."},
+      "Error": {
+        "description": "The request failed.",
+        "content": {"application/json": {"schema": {"type": "object", "properties": {"error": {"type": "string"}}}}}
+      }
+    },
+    "schemas": {
+      "Problem": {
+        "type": "object",
+        "properties": {
+          "line": {"type": "integer"},
+          "severity": {"type": "string", "enum": ["error", "warning", "info"]},
+          "code": {"type": "string"},
+          "message": {"type": "string"}
+        }
+      },
+      "KEP": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string", "description": "The path below the keps directory without .md."},
+          "number": {"type": "string"},
+          "title": {"type": "string"},
+          "sig": {"type": "string"},
+          "status": {"type": "string"},
+          "created": {"type": "string"},
+          "updated": {"type": "string"},
+          "authors": {"type": "array", "items": {"type": "string"}},
+          "reviewers": {"type": "array", "items": {"type": "string"}},
+          "approvers": {"type": "array", "items": {"type": "string"}},
+          "filename": {"type": "string"},
+          "problems": {"type": "array", "items": {"$ref": "#/components/schemas/Problem"}}
+        }
+      },
+      "KEPPage": {
+        "type": "object",
+        "properties": {
+          "total": {"type": "integer"},
+          "offset": {"type": "integer"},
+          "limit": {"type": "integer"},
+          "keps": {"type": "array", "items": {"$ref": "#/components/schemas/KEP"}}
+        }
+      },
+      "SIG": {
+        "type": "object",
+        "properties": {
+          "sig": {"type": "string"},
+          "owned": {"type": "integer"},
+          "participating": {"type": "integer"},
+          "statuses": {"type": "object", "additionalProperties": {"type": "integer"}},
+          "oldestProvisional": {"type": "array", "items": {"type": "object"}},
+          "stale": {"type": "array", "items": {"type": "object"}},
+          "errors": {"type": "integer"}
+        }
+      },
+      "Change": {
+        "type": "object",
+        "properties": {
+          "filename": {"type": "string"},
+          "title": {"type": "string"},
+          "kind": {"type": "string", "enum": ["added", "changed", "removed"]},
+          "new": {"type": "array", "items": {"$ref": "#/components/schemas/Problem"}},
+          "fixed": {"type": "array", "items": {"$ref": "#/components/schemas/Problem"}},
+          "problems": {"type": "integer"}
+        }
+      },
+      "Validation": {
+        "type": "object",
+        "properties": {
+          "valid": {"type": "boolean"},
+          "problems": {"type": "array", "items": {"$ref": "#/components/schemas/Problem"}}
+        }
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,208 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/pkg/errors"
+)
+
+// kepRef identifies a KEP in summaries.
+type kepRef struct {
+	Title    string `json:"title"`
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+}
+
+func refTo(p *keps.Proposal) kepRef {
+	return kepRef{Title: p.Title, Filename: p.Filename, Status: p.Status}
+}
+
+// workload is every KEP a person is on.
+type workload struct {
+	Handle    string         `json:"handle"`
+	Name      string         `json:"name,omitempty"`
+	Authoring []kepRef       `json:"authoring"`
+	Reviewing []kepRef       `json:"reviewing"`
+	Approving []kepRef       `json:"approving"`
+	Statuses  map[string]int `json:"statuses"`
+	// LastActive is the latest last-updated date of the person's KEPs.
+	LastActive string `json:"lastActive,omitempty"`
+}
+
+// peopleIndex is the workload of everyone on a set of KEPs.
+type peopleIndex struct {
+	People []*workload `json:"people"`
+	// InactiveReviewers are active KEPs whose only reviewer hasn't been on
+	// an updated KEP recently.
+	InactiveReviewers []kepRef `json:"inactiveReviewers"`
+}
+
+// indexPeople inverts the KEPs into a workload per GitHub handle, busiest
+// first. Reviewers are inactive when none of their KEPs was updated within
+// inactiveAfter of now.
+func indexPeople(proposals keps.Proposals, now time.Time, inactiveAfter time.Duration) *peopleIndex {
+	byHandle := map[string]*workload{}
+	for _, p := range proposals {
+		if p.HasErrors() {
+			continue
+		}
+		counted := map[string]bool{}
+		for _, person := range p.People() {
+			w, ok := byHandle[person.Handle]
+			if !ok {
+				w = &workload{Handle: person.Handle, Authoring: []kepRef{}, Reviewing: []kepRef{}, Approving: []kepRef{}, Statuses: map[string]int{}}
+				byHandle[person.Handle] = w
+			}
+			if w.Name == "" {
+				w.Name = person.Name
+			}
+			switch person.Role {
+			case keps.RoleAuthor:
+				w.Authoring = append(w.Authoring, refTo(p))
+			case keps.RoleReviewer:
+				w.Reviewing = append(w.Reviewing, refTo(p))
+			case keps.RoleApprover:
+				w.Approving = append(w.Approving, refTo(p))
+			}
+			if !counted[person.Handle] {
+				counted[person.Handle] = true
+				w.Statuses[strings.ToLower(p.Status)]++
+			}
+			if updated := strings.TrimSpace(p.LastUpdated); updated > w.LastActive {
+				if _, ok := parseDate(updated); ok {
+					w.LastActive = updated
+				}
+			}
+		}
+	}
+
+	index := &peopleIndex{People: []*workload{}, InactiveReviewers: []kepRef{}}
+	for _, w := range byHandle {
+		index.People = append(index.People, w)
+	}
+	sort.Slice(index.People, func(i, j int) bool {
+		a, b := index.People[i], index.People[j]
+		if total(a) != total(b) {
+			return total(a) > total(b)
+		}
+		return a.Handle < b.Handle
+	})
+
+	for _, p := range proposals {
+		if p.HasErrors() || !isActive(p.Status) {
+			continue
+		}
+		var reviewers []string
+		for _, person := range p.People() {
+			if person.Role == keps.RoleReviewer {
+				reviewers = append(reviewers, person.Handle)
+			}
+		}
+		if len(reviewers) != 1 {
+			continue
+		}
+		last, ok := parseDate(byHandle[reviewers[0]].LastActive)
+		if !ok || now.Sub(last) > inactiveAfter {
+			index.InactiveReviewers = append(index.InactiveReviewers, refTo(p))
+		}
+	}
+	return index
+}
+
+// otherStatuses counts the KEPs that aren't provisional, implementable or
+// implemented.
+func otherStatuses(statuses map[string]int) int {
+	other := 0
+	for status, n := range statuses {
+		switch status {
+		case "provisional", "implementable", "implemented":
+		default:
+			other += n
+		}
+	}
+	return other
+}
+
+func total(w *workload) int {
+	return len(w.Authoring) + len(w.Reviewing) + len(w.Approving)
+}
+
+// isActive returns true for KEPs that are still being worked on.
+func isActive(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "provisional", "implementable":
+		return true
+	}
+	return false
+}
+
+// people prints the workload of everyone on the KEPs.
+func people(args []string) error {
+	configuration := &config{}
+	fs := configuration.flags("people")
+	format := fs.String("format", "table", "output format: table|json")
+	inactiveAfter := ageValue(365 * 24 * time.Hour)
+	fs.Var(&inactiveAfter, "inactive-after", "how long since a reviewer's KEPs were updated before they count as inactive, such as 180d")
+	fs.Parse(args)
+
+	if *format != "table" && *format != "json" {
+		return errors.Errorf("unknown format %q, must be one of json|table", *format)
+	}
+	proposals, _, err := configuration.proposals()
+	if err != nil {
+		return err
+	}
+	index := indexPeople(proposals, time.Now(), time.Duration(inactiveAfter))
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return errors.WithStack(enc.Encode(index))
+	}
+	return writePeopleTable(os.Stdout, index)
+}
+
+func writePeopleTable(w io.Writer, index *peopleIndex) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "HANDLE\tAUTHORING\tREVIEWING\tAPPROVING\tPROVISIONAL\tIMPLEMENTABLE\tIMPLEMENTED\tOTHER\tLAST ACTIVE")
+	for _, p := range index.People {
+		fmt.Fprintf(tw, "@%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n", p.Handle,
+			len(p.Authoring), len(p.Reviewing), len(p.Approving),
+			p.Statuses["provisional"], p.Statuses["implementable"], p.Statuses["implemented"], otherStatuses(p.Statuses),
+			p.LastActive)
+	}
+	if err := tw.Flush(); err != nil {
+		return errors.WithStack(err)
+	}
+	if len(index.InactiveReviewers) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "\nActive KEPs whose only reviewer is inactive:")
+	for _, ref := range index.InactiveReviewers {
+		fmt.Fprintf(w, "  %s (%s, %s)\n", ref.Title, ref.Status, ref.Filename)
+	}
+	return nil
+}
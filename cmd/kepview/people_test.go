@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chuckha/kepview/keps"
+)
+
+func TestIndexPeople(t *testing.T) {
+	proposals := keps.Proposals{
+		{Title: "a", Status: "implementable", LastUpdated: "2019-05-01", Authors: []string{"@jane"}, Reviewers: []string{"@bob"}, Approvers: []string{"@ann"}},
+		{Title: "b", Status: "provisional", LastUpdated: "2018-01-01", Authors: []string{"Jane (@Jane)"}, Reviewers: []string{"@old"}, Approvers: []string{"@ann"}},
+		{Title: "c", Status: "implemented", LastUpdated: "2017-01-01", Authors: []string{"@old"}, Reviewers: []string{"@jane", "@bob"}, Approvers: []string{"@ann"}},
+	}
+	now := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	index := indexPeople(proposals, now, 365*24*time.Hour)
+
+	if len(index.People) != 4 {
+		t.Fatalf("expected 4 people but got %d", len(index.People))
+	}
+	ann, jane := index.People[0], index.People[1]
+	if ann.Handle != "ann" || len(ann.Approving) != 3 {
+		t.Fatalf("expected ann to approve 3 KEPs but got %+v", ann)
+	}
+	if jane.Handle != "jane" || jane.Name != "Jane" || len(jane.Authoring) != 2 || len(jane.Reviewing) != 1 {
+		t.Fatalf("unexpected workload for jane %+v", jane)
+	}
+	if jane.Statuses["implementable"] != 1 || jane.Statuses["provisional"] != 1 || jane.Statuses["implemented"] != 1 {
+		t.Fatalf("unexpected status counts %v", jane.Statuses)
+	}
+	if jane.LastActive != "2019-05-01" {
+		t.Fatalf("expected jane to be last active on 2019-05-01 but got %q", jane.LastActive)
+	}
+	if len(index.InactiveReviewers) != 1 || index.InactiveReviewers[0].Title != "b" {
+		t.Fatalf("expected b to have an inactive reviewer but got %v", index.InactiveReviewers)
+	}
+
+	var buf bytes.Buffer
+	if err := writePeopleTable(&buf, index); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if !strings.Contains(buf.String(), "@ann") || !strings.Contains(buf.String(), "b (provisional,") {
+		t.Fatalf("unexpected table:\n%s", buf.String())
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	testcases := []struct {
+		in       string
+		expected time.Duration
+		ok       bool
+	}{
+		{"180d", 180 * 24 * time.Hour, true},
+		{"72h", 72 * time.Hour, true},
+		{"soon", 0, false},
+		{"-1d", 0, false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.in, func(t *testing.T) {
+			d, err := parseAge(tc.in)
+			if (err == nil) != tc.ok || d != tc.expected {
+				t.Fatalf("expected %v but got %v, %v", tc.expected, d, err)
+			}
+		})
+	}
+}
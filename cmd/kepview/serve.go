@@ -0,0 +1,378 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+	"github.com/pkg/errors"
+)
+
+var kepNumberRe = regexp.MustCompile(`^0*([0-9]+)-`)
+
+// sortFields are the KEP fields the index can be sorted by.
+var sortFields = map[string]func(p *keps.Proposal, rules *validations.Config) string{
+	"title":   func(p *keps.Proposal, _ *validations.Config) string { return strings.ToLower(p.Title) },
+	"status":  func(p *keps.Proposal, _ *validations.Config) string { return strings.ToLower(p.Status) },
+	"created": func(p *keps.Proposal, _ *validations.Config) string { return strings.TrimSpace(p.CreationDate) },
+	"updated": func(p *keps.Proposal, _ *validations.Config) string { return strings.TrimSpace(p.LastUpdated) },
+	"sig":     owningSIG,
+}
+
+// server is a read-only web UI and JSON API over the KEPs in a directory.
+type server struct {
+	root  string
+	rules *validations.Config
+	now   func() time.Time
+	pages *template.Template
+
+	mu        sync.RWMutex
+	proposals keps.Proposals
+	byID      map[string]*keps.Proposal
+
+	subscribersMu sync.Mutex
+	subscribers   map[chan []change]bool
+}
+
+func newServer(root string, rules *validations.Config, proposals keps.Proposals) *server {
+	s := &server{root: root, rules: rules, now: time.Now, subscribers: map[chan []change]bool{}}
+	s.pages = template.Must(template.New("kepview").Funcs(template.FuncMap{
+		// kepURL links to a KEP by its filename.
+		"kepURL": func(filename string) string {
+			return "/kep/" + s.id(&keps.Proposal{Filename: filename})
+		},
+	}).Parse(pageTemplates))
+	s.update(proposals)
+	return s
+}
+
+// update replaces the KEPs being served.
+func (s *server) update(proposals keps.Proposals) {
+	byID := make(map[string]*keps.Proposal, len(proposals))
+	for _, p := range proposals {
+		byID[s.id(p)] = p
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.proposals, s.byID = proposals, byID
+}
+
+// subscribe returns a channel that receives the changes to the KEPs until
+// unsubscribe is called.
+func (s *server) subscribe() chan []change {
+	ch := make(chan []change, 16)
+	s.subscribersMu.Lock()
+	defer s.subscribersMu.Unlock()
+	s.subscribers[ch] = true
+	return ch
+}
+
+func (s *server) unsubscribe(ch chan []change) {
+	s.subscribersMu.Lock()
+	defer s.subscribersMu.Unlock()
+	delete(s.subscribers, ch)
+}
+
+// publish sends changes to every subscriber, skipping any that have fallen
+// behind.
+func (s *server) publish(changes []change) {
+	s.subscribersMu.Lock()
+	defer s.subscribersMu.Unlock()
+	for ch := range s.subscribers {
+		select {
+		case ch <- changes:
+		default:
+		}
+	}
+}
+
+// snapshot returns the KEPs being served.
+func (s *server) snapshot() keps.Proposals {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.proposals
+}
+
+func (s *server) lookup(id string) *keps.Proposal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.byID[id]
+}
+
+// id identifies a KEP by its path below the keps directory without the .md
+// extension, such as sig-node/0001-my-kep.
+func (s *server) id(p *keps.Proposal) string {
+	rel, err := filepath.Rel(s.root, p.Filename)
+	if err != nil {
+		rel = p.Filename
+	}
+	return strings.TrimSuffix(filepath.ToSlash(rel), ".md")
+}
+
+// number returns the KEP number from a filename such as 0014-foo.md, or
+// nothing.
+func (s *server) number(p *keps.Proposal) string {
+	if m := kepNumberRe.FindStringSubmatch(path.Base(s.id(p))); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/kep/", s.handleKEP)
+	mux.HandleFunc("/sigs", s.handleSIGs)
+	mux.HandleFunc("/people", s.handlePeople)
+	mux.HandleFunc("/people/", s.handlePerson)
+	s.apiRoutes(mux)
+	return mux
+}
+
+// kepSummary is a KEP as the index and API show it.
+type kepSummary struct {
+	ID        string    `json:"id"`
+	Number    string    `json:"number,omitempty"`
+	Title     string    `json:"title"`
+	SIG       string    `json:"sig"`
+	Status    string    `json:"status"`
+	Created   string    `json:"created"`
+	Updated   string    `json:"updated"`
+	Authors   []string  `json:"authors"`
+	Reviewers []string  `json:"reviewers"`
+	Approvers []string  `json:"approvers"`
+	Filename  string    `json:"filename"`
+	Problems  []problem `json:"problems"`
+}
+
+// problem is a validation problem found in a KEP.
+type problem struct {
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+func (s *server) summarise(p *keps.Proposal) *kepSummary {
+	return &kepSummary{
+		ID:        s.id(p),
+		Number:    s.number(p),
+		Title:     p.Title,
+		SIG:       owningSIG(p, s.rules),
+		Status:    p.Status,
+		Created:   p.CreationDate,
+		Updated:   p.LastUpdated,
+		Authors:   nonNil(p.Authors),
+		Reviewers: nonNil(p.Reviewers),
+		Approvers: nonNil(p.Approvers),
+		Filename:  p.Filename,
+		Problems:  problems(p.Error),
+	}
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func problems(err error) []problem {
+	out := []problem{}
+	if err == nil {
+		return out
+	}
+	errs, ok := err.(validations.ErrorList)
+	if !ok {
+		return append(out, problem{Severity: validations.SeverityError.String(), Code: validations.Code(err), Message: err.Error()})
+	}
+	for _, e := range errs {
+		out = append(out, problem{Line: e.Line, Severity: e.Severity.String(), Code: e.Code(), Message: e.Err.Error()})
+	}
+	return out
+}
+
+// query filters and sorts KEPs by the sig, status, q, sort and order query
+// parameters.
+func (s *server) query(params url.Values) ([]*kepSummary, error) {
+	field := params.Get("sort")
+	if field == "" {
+		field = "title"
+	}
+	key, ok := sortFields[field]
+	if !ok {
+		return nil, errors.Errorf("unknown sort %q, must be one of created, sig, status, title or updated", field)
+	}
+	order := params.Get("order")
+	if order != "" && order != "asc" && order != "desc" {
+		return nil, errors.Errorf("unknown order %q, must be asc or desc", order)
+	}
+	sig := s.rules.CanonicalSIG(params.Get("sig"))
+	status := strings.ToLower(strings.TrimSpace(params.Get("status")))
+	q := strings.ToLower(strings.TrimSpace(params.Get("q")))
+
+	var matched keps.Proposals
+	for _, p := range s.snapshot() {
+		if sig != "" && owningSIG(p, s.rules) != sig {
+			continue
+		}
+		if status != "" && strings.ToLower(strings.TrimSpace(p.Status)) != status {
+			continue
+		}
+		if q != "" && !strings.Contains(strings.ToLower(p.Title), q) && !strings.Contains(strings.ToLower(s.id(p)), q) {
+			continue
+		}
+		matched = append(matched, p)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := key(matched[i], s.rules), key(matched[j], s.rules)
+		if a == b {
+			return s.id(matched[i]) < s.id(matched[j])
+		}
+		if order == "desc" {
+			return a > b
+		}
+		return a < b
+	})
+	out := make([]*kepSummary, 0, len(matched))
+	for _, p := range matched {
+		out = append(out, s.summarise(p))
+	}
+	return out, nil
+}
+
+func (s *server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	summaries, err := s.query(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var sigNames []string
+	for _, h := range aggregateSIGs(s.snapshot(), s.rules, s.now(), validations.StaleAfter) {
+		sigNames = append(sigNames, h.SIG)
+	}
+	s.render(w, "index", map[string]interface{}{
+		"KEPs":   summaries,
+		"SIGs":   sigNames,
+		"Query":  r.URL.Query(),
+		"Fields": []string{"title", "sig", "status", "created", "updated"},
+	})
+}
+
+func (s *server) handleKEP(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean("/" + strings.TrimPrefix(r.URL.Path, "/kep/"))[1:]
+	if p := s.lookup(strings.TrimSuffix(name, ".md")); p != nil {
+		body := p.Contents
+		if p.FrontMatter != nil {
+			body = string(p.FrontMatter.Body)
+		}
+		s.render(w, "kep", map[string]interface{}{
+			"KEP":  s.summarise(p),
+			"Body": renderMarkdown(body),
+		})
+		return
+	}
+	// Anything else the KEPs link to, such as images, is served from the
+	// keps directory.
+	file := filepath.Join(s.root, filepath.FromSlash(name))
+	if info, err := os.Stat(file); name == "" || err != nil || info.IsDir() || strings.HasSuffix(name, ".md") {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFile(w, r, file)
+}
+
+func (s *server) handleSIGs(w http.ResponseWriter, r *http.Request) {
+	s.render(w, "sigs", aggregateSIGs(s.snapshot(), s.rules, s.now(), validations.StaleAfter))
+}
+
+func (s *server) people() *peopleIndex {
+	return indexPeople(s.snapshot(), s.now(), 365*24*time.Hour)
+}
+
+func (s *server) handlePeople(w http.ResponseWriter, r *http.Request) {
+	s.render(w, "people", s.people())
+}
+
+func (s *server) handlePerson(w http.ResponseWriter, r *http.Request) {
+	handle := strings.ToLower(strings.TrimPrefix(r.URL.Path, "/people/"))
+	for _, person := range s.people().People {
+		if person.Handle == handle {
+			s.render(w, "person", person)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func (s *server) render(w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.pages.ExecuteTemplate(w, name, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// serve runs a read-only web UI over the KEPs.
+func serve(args []string) error {
+	configuration := &config{}
+	fs := configuration.flags("serve")
+	addr := fs.String("addr", ":8080", "the address to listen on")
+	watchChanges := fs.Bool("watch", false, "re-parse KEPs as they change and push the changes to "+apiPrefix+"/events")
+	options := &watchOptions{}
+	options.flags(fs)
+	fs.Parse(args)
+
+	proposals, rules, err := configuration.proposals()
+	if err != nil {
+		return err
+	}
+	s := newServer(configuration.root, rules, proposals)
+	if *watchChanges {
+		w, err := options.watcher(configuration.root)
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+		ix := newKEPIndex(configuration.finder(rules), proposals)
+		go watchKEPs(w, ix, func(changes []change) {
+			s.update(ix.proposals())
+			s.publish(changes)
+			writeChanges(os.Stdout, changes)
+		}, func(err error) {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		})
+	}
+	fmt.Printf("Serving %d KEPs on %s\n", len(proposals), *addr)
+	return errors.WithStack(http.ListenAndServe(*addr, s.routes()))
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+func testServer(t *testing.T) (*server, func()) {
+	root, err := ioutil.TempDir("", "kepview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "sig-node", "images"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "sig-node", "images", "d.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rules := validations.DefaultConfig()
+	rules.SetSIGRegistry(validations.NewSIGRegistry("sig-network", "sig-node"))
+	parse := func(filename, doc string) *keps.Proposal {
+		p := (&keps.Parser{Config: rules}).Parse(strings.NewReader(doc))
+		p.Filename = filepath.Join(root, filename)
+		return p
+	}
+	proposals := keps.Proposals{
+		parse("sig-node/0001-cpu.md", "---\ntitle: CPU manager\nauthors:\n  - \"@alice\"\nowning-sig: sig-node\nreviewers:\n  - \"@bob\"\napprovers:\n  - \"@carol\"\neditor: TBD\ncreation-date: 2019-01-01\nlast-updated: 2019-02-01\nstatus: implementable\n---\n\n# CPU manager\n\nSee the ![diagram](images/d.png).\n"),
+		parse("sig-network/0002-dns.md", "---\ntitle: DNS\nauthors:\n  - \"@bob\"\nowning-sig: sig-network\nreviewers:\n  - \"@alice\"\napprovers:\n  - \"@dave\"\neditor: TBD\ncreation-date: 2018-01-01\nlast-updated: 2019-03-01\nstatus: provisional\n---\n\n# DNS\n"),
+	}
+	s := newServer(root, rules, proposals)
+	s.now = func() time.Time { return time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC) }
+	return s, func() { os.RemoveAll(root) }
+}
+
+func get(t *testing.T, h http.Handler, url string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+	return rec
+}
+
+func TestServePages(t *testing.T) {
+	s, cleanup := testServer(t)
+	defer cleanup()
+	testcases := []struct {
+		url      string
+		status   int
+		contains []string
+		excludes []string
+	}{
+		{"/", http.StatusOK, []string{"CPU manager", "DNS", `href="/kep/sig-node/0001-cpu"`}, nil},
+		{"/?sig=node", http.StatusOK, []string{"CPU manager"}, []string{">DNS<"}},
+		{"/?status=provisional", http.StatusOK, []string{">DNS<"}, []string{"CPU manager"}},
+		{"/?q=cpu", http.StatusOK, []string{"CPU manager"}, []string{">DNS<"}},
+		{"/?sort=bogus", http.StatusBadRequest, nil, nil},
+		{"/kep/sig-node/0001-cpu", http.StatusOK, []string{`<h1 id="cpu-manager">CPU manager</h1>`, `<img src="images/d.png"`, "@carol"}, nil},
+		{"/kep/sig-node/0001-cpu.md", http.StatusOK, []string{"CPU manager"}, nil},
+		{"/kep/sig-node/images/d.png", http.StatusOK, []string{"png"}, nil},
+		{"/kep/sig-node/missing", http.StatusNotFound, nil, nil},
+		{"/sigs", http.StatusOK, []string{"sig-network", "sig-node"}, nil},
+		{"/people", http.StatusOK, []string{`href="/people/alice"`}, nil},
+		{"/people/bob", http.StatusOK, []string{"DNS", "CPU manager"}, nil},
+		{"/people/nobody", http.StatusNotFound, nil, nil},
+		{"/nothing", http.StatusNotFound, nil, nil},
+	}
+	h := s.routes()
+	for _, tc := range testcases {
+		t.Run(tc.url, func(t *testing.T) {
+			rec := get(t, h, tc.url)
+			if rec.Code != tc.status {
+				t.Fatalf("expected status %d but got %d: %s", tc.status, rec.Code, rec.Body)
+			}
+			for _, want := range tc.contains {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("expected %q in:\n%s", want, rec.Body)
+				}
+			}
+			for _, unwanted := range tc.excludes {
+				if strings.Contains(rec.Body.String(), unwanted) {
+					t.Errorf("did not expect %q in:\n%s", unwanted, rec.Body)
+				}
+			}
+		})
+	}
+	// The mux redirects to the cleaned path, so call the handler directly.
+	if rec := get(t, http.HandlerFunc(s.handleKEP), "/kep/../../etc/passwd"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected paths outside the keps directory to be a 404 but got %d", rec.Code)
+	}
+}
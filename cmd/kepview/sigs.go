@@ -0,0 +1,184 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+	"github.com/pkg/errors"
+)
+
+// oldestProvisional is how many of the oldest provisional KEPs each SIG lists.
+const oldestProvisional = 3
+
+// datedRef is a KEP with the date that makes it interesting.
+type datedRef struct {
+	kepRef
+	Date string `json:"date"`
+}
+
+// sigHealth summarises the enhancement backlog of a SIG.
+type sigHealth struct {
+	SIG           string         `json:"sig"`
+	Owned         int            `json:"owned"`
+	Participating int            `json:"participating"`
+	Statuses      map[string]int `json:"statuses"`
+	// OldestProvisional are the provisional KEPs created longest ago.
+	OldestProvisional []datedRef `json:"oldestProvisional"`
+	// Stale are active KEPs whose last-updated is older than the cutoff.
+	Stale []datedRef `json:"stale"`
+	// Errors counts the validation errors in the SIG's KEPs.
+	Errors int `json:"errors"`
+}
+
+// aggregateSIGs groups the KEPs by canonical owning SIG and counts the
+// participating SIGs, ordered by SIG name.
+func aggregateSIGs(proposals keps.Proposals, rules *validations.Config, now time.Time, staleAfter time.Duration) []*sigHealth {
+	bySIG := map[string]*sigHealth{}
+	get := func(sig string) *sigHealth {
+		h, ok := bySIG[sig]
+		if !ok {
+			h = &sigHealth{SIG: sig, Statuses: map[string]int{}, OldestProvisional: []datedRef{}, Stale: []datedRef{}}
+			bySIG[sig] = h
+		}
+		return h
+	}
+	for _, p := range proposals {
+		h := get(owningSIG(p, rules))
+		h.Owned++
+		h.Errors += errorCount(p)
+		if p.HasErrors() {
+			continue
+		}
+		status := strings.ToLower(strings.TrimSpace(p.Status))
+		h.Statuses[status]++
+		if status == "provisional" {
+			if _, ok := parseDate(p.CreationDate); ok {
+				h.OldestProvisional = append(h.OldestProvisional, datedRef{refTo(p), strings.TrimSpace(p.CreationDate)})
+			}
+		}
+		if updated, ok := parseDate(p.LastUpdated); ok && isActive(status) && now.Sub(updated) > staleAfter {
+			h.Stale = append(h.Stale, datedRef{refTo(p), strings.TrimSpace(p.LastUpdated)})
+		}
+		seen := map[string]bool{}
+		for _, sig := range p.ParticipatingSIGs {
+			canonical := rules.CanonicalSIG(sig)
+			if canonical == "" || canonical == h.SIG || seen[canonical] {
+				continue
+			}
+			seen[canonical] = true
+			get(canonical).Participating++
+		}
+	}
+
+	out := make([]*sigHealth, 0, len(bySIG))
+	for _, h := range bySIG {
+		byDate := func(refs []datedRef) {
+			sort.SliceStable(refs, func(i, j int) bool { return refs[i].Date < refs[j].Date })
+		}
+		byDate(h.OldestProvisional)
+		byDate(h.Stale)
+		if len(h.OldestProvisional) > oldestProvisional {
+			h.OldestProvisional = h.OldestProvisional[:oldestProvisional]
+		}
+		out = append(out, h)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].SIG < out[j].SIG })
+	return out
+}
+
+// owningSIG returns the canonical owning SIG of a KEP. KEPs whose metadata
+// couldn't be read are attributed to the sig- directory they are in.
+func owningSIG(p *keps.Proposal, rules *validations.Config) string {
+	if sig := rules.CanonicalSIG(p.OwningSIG); sig != "" {
+		return sig
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(p.Filename)), "/") {
+		if strings.HasPrefix(dir, "sig-") {
+			return rules.CanonicalSIG(dir)
+		}
+	}
+	return "unknown"
+}
+
+// errorCount returns the number of error severity problems in a KEP.
+func errorCount(p *keps.Proposal) int {
+	if p.Error == nil {
+		return 0
+	}
+	errs, ok := p.Error.(validations.ErrorList)
+	if !ok {
+		return 1
+	}
+	n := 0
+	for _, err := range errs {
+		if err.Severity == validations.SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// sigs prints a health summary of each SIG's KEPs.
+func sigs(args []string) error {
+	configuration := &config{}
+	fs := configuration.flags("sigs")
+	format := fs.String("format", "table", "output format: table|json")
+	staleAfter := ageValue(validations.StaleAfter)
+	fs.Var(&staleAfter, "stale-after", "how old last-updated can be before an active KEP is stale, such as 180d")
+	fs.Parse(args)
+
+	if *format != "table" && *format != "json" {
+		return errors.Errorf("unknown format %q, must be one of json|table", *format)
+	}
+	proposals, rules, err := configuration.proposals()
+	if err != nil {
+		return err
+	}
+	health := aggregateSIGs(proposals, rules, time.Now(), time.Duration(staleAfter))
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return errors.WithStack(enc.Encode(health))
+	}
+	return writeSIGsTable(os.Stdout, health)
+}
+
+func writeSIGsTable(w io.Writer, health []*sigHealth) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SIG\tOWNED\tPARTICIPATING\tPROVISIONAL\tIMPLEMENTABLE\tIMPLEMENTED\tOTHER\tSTALE\tERRORS\tOLDEST PROVISIONAL")
+	for _, h := range health {
+		oldest := ""
+		if len(h.OldestProvisional) > 0 {
+			oldest = fmt.Sprintf("%s (%s)", h.OldestProvisional[0].Title, h.OldestProvisional[0].Date)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n", h.SIG, h.Owned, h.Participating,
+			h.Statuses["provisional"], h.Statuses["implementable"], h.Statuses["implemented"], otherStatuses(h.Statuses),
+			len(h.Stale), h.Errors, oldest)
+	}
+	return errors.WithStack(tw.Flush())
+}
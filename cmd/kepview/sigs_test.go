@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+func TestAggregateSIGs(t *testing.T) {
+	rules := validations.DefaultConfig()
+	rules.SetSIGRegistry(validations.NewSIGRegistry("sig-network", "sig-node"))
+	proposals := keps.Proposals{
+		{Title: "a", OwningSIG: "sig-network", Status: "provisional", CreationDate: "2018-03-01", LastUpdated: "2018-03-01", ParticipatingSIGs: []string{"node", "sig-network"}},
+		{Title: "b", OwningSIG: "network", Status: "provisional", CreationDate: "2017-01-01", LastUpdated: "2019-05-01"},
+		{Title: "c", OwningSIG: "sig-network", Status: "implemented", CreationDate: "2016-01-01", LastUpdated: "2016-01-01"},
+		{Title: "d", Filename: "keps/sig-node/d.md", Error: errors.New("error reading file")},
+	}
+	now := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	health := aggregateSIGs(proposals, rules, now, 365*24*time.Hour)
+	if len(health) != 2 {
+		t.Fatalf("expected 2 SIGs but got %d", len(health))
+	}
+	network, node := health[0], health[1]
+	if network.SIG != "sig-network" || network.Owned != 3 || network.Participating != 0 {
+		t.Fatalf("unexpected summary %+v", network)
+	}
+	if network.Statuses["provisional"] != 2 || network.Statuses["implemented"] != 1 {
+		t.Fatalf("unexpected status counts %v", network.Statuses)
+	}
+	if len(network.OldestProvisional) != 2 || network.OldestProvisional[0].Title != "b" {
+		t.Fatalf("expected b to be the oldest provisional KEP but got %v", network.OldestProvisional)
+	}
+	if len(network.Stale) != 1 || network.Stale[0].Title != "a" {
+		t.Fatalf("expected only a to be stale but got %v", network.Stale)
+	}
+	if node.SIG != "sig-node" || node.Owned != 1 || node.Participating != 1 || node.Errors != 1 {
+		t.Fatalf("unexpected summary %+v", node)
+	}
+
+	var buf bytes.Buffer
+	if err := writeSIGsTable(&buf, health); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if !strings.Contains(buf.String(), "b (2017-01-01)") {
+		t.Fatalf("unexpected table:\n%s", buf.String())
+	}
+}
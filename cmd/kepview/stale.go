@@ -0,0 +1,189 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+	"github.com/pkg/errors"
+)
+
+// staleKEP is an active KEP that hasn't moved.
+type staleKEP struct {
+	kepRef
+	SIG string `json:"sig"`
+	// Updated is when the KEP last changed and Source says where that came
+	// from: its last-updated metadata or the git history.
+	Updated string `json:"updated"`
+	Source  string `json:"source"`
+	Days    int    `json:"days"`
+	// Authors are the handles of the KEP's authors.
+	Authors []string `json:"authors"`
+}
+
+// staleReport is every stale KEP grouped by SIG and by author.
+type staleReport struct {
+	BySIG    map[string][]*staleKEP `json:"bySIG"`
+	ByAuthor map[string][]*staleKEP `json:"byAuthor"`
+}
+
+// thresholdsValue is a flag of per status ages such as
+// provisional=90d,implementable=365d.
+type thresholdsValue map[string]time.Duration
+
+func (t thresholdsValue) String() string {
+	parts := make([]string, 0, len(t))
+	for status, d := range t {
+		a := ageValue(d)
+		parts = append(parts, status+"="+a.String())
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func (t thresholdsValue) Set(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || !isActive(kv[0]) {
+			return errors.Errorf("invalid threshold %q, use provisional=90d or implementable=365d", part)
+		}
+		d, err := parseAge(kv[1])
+		if err != nil {
+			return err
+		}
+		t[strings.ToLower(kv[0])] = d
+	}
+	return nil
+}
+
+// lastCommitFunc returns when a file was last committed.
+type lastCommitFunc func(path string) (time.Time, error)
+
+// findStale returns the provisional and implementable KEPs that haven't been
+// updated for longer than their status's threshold, or olderThan.
+func findStale(proposals keps.Proposals, rules *validations.Config, now time.Time, olderThan time.Duration, thresholds map[string]time.Duration, lastCommit lastCommitFunc) *staleReport {
+	report := &staleReport{BySIG: map[string][]*staleKEP{}, ByAuthor: map[string][]*staleKEP{}}
+	for _, p := range proposals {
+		status := strings.ToLower(strings.TrimSpace(p.Status))
+		if p.HasErrors() || !isActive(status) {
+			continue
+		}
+		threshold, ok := thresholds[status]
+		if !ok {
+			threshold = olderThan
+		}
+		updated, source := time.Time{}, "last-updated"
+		if t, ok := parseDate(p.LastUpdated); ok {
+			updated = t
+		} else if t, err := lastCommit(p.Filename); err == nil {
+			updated, source = t, "git"
+		} else {
+			continue
+		}
+		age := now.Sub(updated)
+		if age <= threshold {
+			continue
+		}
+		kep := &staleKEP{
+			kepRef:  refTo(p),
+			SIG:     owningSIG(p, rules),
+			Updated: updated.Format(dateFormat),
+			Source:  source,
+			Days:    int(age / (24 * time.Hour)),
+			Authors: []string{},
+		}
+		report.BySIG[kep.SIG] = append(report.BySIG[kep.SIG], kep)
+		for _, person := range p.People() {
+			if person.Role == keps.RoleAuthor {
+				kep.Authors = append(kep.Authors, person.Handle)
+				report.ByAuthor[person.Handle] = append(report.ByAuthor[person.Handle], kep)
+			}
+		}
+	}
+	for _, groups := range []map[string][]*staleKEP{report.BySIG, report.ByAuthor} {
+		for _, group := range groups {
+			sort.SliceStable(group, func(i, j int) bool { return group[i].Days > group[j].Days })
+		}
+	}
+	return report
+}
+
+// stale prints the active KEPs that haven't moved.
+func stale(args []string) error {
+	configuration := &config{}
+	fs := configuration.flags("stale")
+	format := fs.String("format", "table", "output format: table|json")
+	olderThan := ageValue(180 * 24 * time.Hour)
+	fs.Var(&olderThan, "older-than", "how long an active KEP can go without an update, such as 180d")
+	thresholds := thresholdsValue{}
+	fs.Var(thresholds, "threshold", "per status ages overriding -older-than, such as provisional=90d,implementable=365d")
+	fs.Parse(args)
+
+	if *format != "table" && *format != "json" {
+		return errors.Errorf("unknown format %q, must be one of json|table", *format)
+	}
+	proposals, rules, err := configuration.proposals()
+	if err != nil {
+		return err
+	}
+	report := findStale(proposals, rules, time.Now(), time.Duration(olderThan), thresholds, keps.LastCommitTime)
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return errors.WithStack(enc.Encode(report))
+	}
+	return writeStaleTable(os.Stdout, report)
+}
+
+func writeStaleTable(w io.Writer, report *staleReport) error {
+	if len(report.BySIG) == 0 {
+		fmt.Fprintln(w, "No stale KEPs")
+		return nil
+	}
+	for _, sig := range sortedKeys(report.BySIG) {
+		fmt.Fprintf(w, "%s:\n", sig)
+		for _, kep := range report.BySIG[sig] {
+			authors := "no authors"
+			if len(kep.Authors) > 0 {
+				authors = "@" + strings.Join(kep.Authors, ", @")
+			}
+			fmt.Fprintf(w, "  %s (%s, %d days since %s from %s, %s)\n", kep.Title, kep.Status, kep.Days, kep.Updated, kep.Source, authors)
+		}
+	}
+	fmt.Fprintln(w, "\nBy author:")
+	for _, author := range sortedKeys(report.ByAuthor) {
+		fmt.Fprintf(w, "  @%s: %d stale KEP(s)\n", author, len(report.ByAuthor[author]))
+	}
+	return nil
+}
+
+func sortedKeys(m map[string][]*staleKEP) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
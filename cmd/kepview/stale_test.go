@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+func TestFindStale(t *testing.T) {
+	proposals := keps.Proposals{
+		{Title: "old provisional", Filename: "a.md", OwningSIG: "sig-node", Status: "provisional", LastUpdated: "2018-10-01", Authors: []string{"@jane"}},
+		{Title: "recent", Filename: "b.md", OwningSIG: "sig-node", Status: "provisional", LastUpdated: "2019-05-01", Authors: []string{"@jane"}},
+		{Title: "implementable", Filename: "c.md", OwningSIG: "sig-network", Status: "implementable", LastUpdated: "2018-10-01", Authors: []string{"@bob"}},
+		{Title: "no date", Filename: "d.md", OwningSIG: "sig-network", Status: "implementable", LastUpdated: "TBD", Authors: []string{"@bob"}},
+		{Title: "no history", Filename: "e.md", OwningSIG: "sig-network", Status: "implementable", Authors: []string{"@bob"}},
+		{Title: "implemented", Filename: "f.md", OwningSIG: "sig-node", Status: "implemented", LastUpdated: "2010-01-01"},
+	}
+	lastCommit := func(path string) (time.Time, error) {
+		if path == "d.md" {
+			return time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC), nil
+		}
+		return time.Time{}, errors.New("no commits")
+	}
+	now := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	thresholds := thresholdsValue{}
+	if err := thresholds.Set("implementable=365d"); err != nil {
+		t.Fatal(err)
+	}
+	report := findStale(proposals, validations.DefaultConfig(), now, 180*24*time.Hour, thresholds, lastCommit)
+
+	node := report.BySIG["sig-node"]
+	if len(node) != 1 || node[0].Title != "old provisional" || node[0].Source != "last-updated" || node[0].Days != 243 {
+		t.Fatalf("unexpected stale KEPs for sig-node %+v", node)
+	}
+	network := report.BySIG["sig-network"]
+	if len(network) != 1 || network[0].Title != "no date" || network[0].Source != "git" || network[0].Updated != "2017-01-01" {
+		t.Fatalf("unexpected stale KEPs for sig-network %+v", network)
+	}
+	if len(report.ByAuthor["jane"]) != 1 || len(report.ByAuthor["bob"]) != 1 {
+		t.Fatalf("unexpected stale KEPs by author %v", report.ByAuthor)
+	}
+
+	var buf bytes.Buffer
+	if err := writeStaleTable(&buf, report); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if !strings.Contains(buf.String(), "old provisional (provisional, 243 days since 2018-10-01 from last-updated, @jane)") {
+		t.Fatalf("unexpected table:\n%s", buf.String())
+	}
+}
+
+func TestThresholdsValue(t *testing.T) {
+	thresholds := thresholdsValue{}
+	if err := thresholds.Set("provisional=90d,implementable=1y"); err == nil {
+		t.Fatal("expected an error for 1y")
+	}
+	if err := thresholds.Set("implemented=90d"); err == nil {
+		t.Fatal("expected an error for an inactive status")
+	}
+}
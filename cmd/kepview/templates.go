@@ -0,0 +1,124 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+// pageTemplates are the pages kepview serve renders.
+const pageTemplates = `
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.}} - KEPs</title>
+<style>
+body { font-family: sans-serif; margin: 0 auto; max-width: 70em; padding: 0 1em; }
+nav a { margin-right: 1em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ddd; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+pre { background: #f6f8fa; overflow: auto; padding: 0.6em; }
+.error { color: #b00; }
+.warning { color: #a60; }
+</style>
+</head>
+<body>
+<nav><a href="/">KEPs</a><a href="/sigs">SIGs</a><a href="/people">People</a></nav>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "refs"}}<ul>{{range .}}<li><a href="{{kepURL .Filename}}">{{.Title}}</a> ({{.Status}})</li>{{end}}</ul>{{end}}
+
+{{define "index"}}{{template "header" "KEPs"}}
+<h1>KEPs</h1>
+<form method="get" action="/">
+<input type="search" name="q" placeholder="Search titles" value="{{.Query.Get "q"}}">
+<select name="sig"><option value="">All SIGs</option>
+{{- $sig := .Query.Get "sig"}}{{range .SIGs}}<option{{if eq . $sig}} selected{{end}}>{{.}}</option>{{end}}</select>
+<input type="text" name="status" placeholder="Status" value="{{.Query.Get "status"}}">
+<select name="sort">{{$sort := .Query.Get "sort"}}{{range .Fields}}<option{{if eq . $sort}} selected{{end}}>{{.}}</option>{{end}}</select>
+<select name="order"><option value="asc">ascending</option><option value="desc"{{if eq (.Query.Get "order") "desc"}} selected{{end}}>descending</option></select>
+<button type="submit">Filter</button>
+</form>
+<p>{{len .KEPs}} KEPs</p>
+<table>
+<tr><th>Title</th><th>SIG</th><th>Status</th><th>Created</th><th>Updated</th><th>Problems</th></tr>
+{{- range .KEPs}}
+<tr><td><a href="/kep/{{.ID}}">{{if .Title}}{{.Title}}{{else}}{{.ID}}{{end}}</a></td><td>{{.SIG}}</td><td>{{.Status}}</td><td>{{.Created}}</td><td>{{.Updated}}</td><td>{{len .Problems}}</td></tr>
+{{- end}}
+</table>
+{{template "footer"}}{{end}}
+
+{{define "kep"}}{{template "header" .KEP.Title}}
+{{with .KEP}}
+<h1>{{.Title}}</h1>
+<table>
+<tr><th>SIG</th><td>{{.SIG}}</td></tr>
+<tr><th>Status</th><td>{{.Status}}</td></tr>
+<tr><th>Created</th><td>{{.Created}}</td></tr>
+<tr><th>Last updated</th><td>{{.Updated}}</td></tr>
+<tr><th>Authors</th><td>{{range .Authors}}{{.}} {{end}}</td></tr>
+<tr><th>Reviewers</th><td>{{range .Reviewers}}{{.}} {{end}}</td></tr>
+<tr><th>Approvers</th><td>{{range .Approvers}}{{.}} {{end}}</td></tr>
+<tr><th>File</th><td>{{.Filename}}</td></tr>
+</table>
+{{if .Problems}}
+<h2>Problems</h2>
+<ul>{{range .Problems}}<li class="{{.Severity}}">{{if .Line}}line {{.Line}}: {{end}}{{.Message}} ({{.Code}})</li>{{end}}</ul>
+{{end}}
+{{end}}
+<hr>
+{{.Body}}
+{{template "footer"}}{{end}}
+
+{{define "sigs"}}{{template "header" "SIGs"}}
+<h1>SIGs</h1>
+<table>
+<tr><th>SIG</th><th>Owned</th><th>Participating</th><th>Statuses</th><th>Stale</th><th>Errors</th></tr>
+{{- range .}}
+<tr><td><a href="/?sig={{.SIG}}">{{.SIG}}</a></td><td>{{.Owned}}</td><td>{{.Participating}}</td>
+<td>{{range $status, $n := .Statuses}}{{$status}}: {{$n}}<br>{{end}}</td>
+<td>{{range .Stale}}<a href="{{kepURL .Filename}}">{{.Title}}</a> ({{.Date}})<br>{{end}}</td><td>{{.Errors}}</td></tr>
+{{- end}}
+</table>
+{{template "footer"}}{{end}}
+
+{{define "people"}}{{template "header" "People"}}
+<h1>People</h1>
+<table>
+<tr><th>Handle</th><th>Authoring</th><th>Reviewing</th><th>Approving</th><th>Last active</th></tr>
+{{- range .People}}
+<tr><td><a href="/people/{{.Handle}}">{{.Handle}}</a></td><td>{{len .Authoring}}</td><td>{{len .Reviewing}}</td><td>{{len .Approving}}</td><td>{{.LastActive}}</td></tr>
+{{- end}}
+</table>
+{{if .InactiveReviewers}}
+<h2>KEPs whose only reviewer is inactive</h2>
+{{template "refs" .InactiveReviewers}}
+{{end}}
+{{template "footer"}}{{end}}
+
+{{define "person"}}{{template "header" .Handle}}
+<h1>{{.Handle}}{{if .Name}} ({{.Name}}){{end}}</h1>
+<p>Last active {{if .LastActive}}{{.LastActive}}{{else}}unknown{{end}}</p>
+<h2>Authoring</h2>
+{{template "refs" .Authoring}}
+<h2>Reviewing</h2>
+{{template "refs" .Reviewing}}
+<h2>Approving</h2>
+{{template "refs" .Approving}}
+{{template "footer"}}{{end}}
+`
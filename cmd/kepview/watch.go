@@ -0,0 +1,381 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/pkg/errors"
+)
+
+const (
+	// debounce is how long to wait for more changes before re-parsing, so an
+	// editor saving several files re-parses each of them once.
+	debounce = 100 * time.Millisecond
+	// maxDebounce is the longest a change waits to be re-parsed while others
+	// keep arriving, such as during a git checkout or a build writing files.
+	maxDebounce = time.Second
+)
+
+// Kinds of change to a KEP.
+const (
+	changeAdded   = "added"
+	changeChanged = "changed"
+	changeRemoved = "removed"
+)
+
+// watcher reports the paths below a directory that changed. A changed
+// directory means anything below it may have changed.
+type watcher interface {
+	Events() <-chan string
+	Errors() <-chan error
+	Close() error
+}
+
+// watchOptions are the flags that choose how to watch for changes.
+type watchOptions struct {
+	poll     bool
+	interval time.Duration
+}
+
+func (o *watchOptions) flags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.poll, "poll", false, "scan for changes instead of using file system notifications")
+	fs.DurationVar(&o.interval, "interval", time.Second, "with -poll, how often to scan for changes")
+}
+
+// watcher watches root with inotify where it is available, falling back to
+// scanning root every interval.
+func (o *watchOptions) watcher(root string) (watcher, error) {
+	if !o.poll {
+		w, err := newNotifyWatcher(root)
+		if err == nil {
+			return w, nil
+		}
+		fmt.Fprintf(os.Stderr, "Falling back to polling: %v\n", err)
+	}
+	return newPollWatcher(root, o.interval)
+}
+
+// change is what happened to a KEP when it was re-parsed.
+type change struct {
+	Filename string `json:"filename"`
+	Title    string `json:"title"`
+	Kind     string `json:"kind"`
+	// New are the problems the change introduced and Fixed the ones it
+	// resolved. Problems are matched by code and message, not line, so
+	// moving a problem around doesn't report it.
+	New   []problem `json:"new"`
+	Fixed []problem `json:"fixed"`
+	// Problems is how many problems the KEP has now.
+	Problems int `json:"problems"`
+}
+
+// kepIndex is the parsed KEPs below a directory, kept up to date one file at
+// a time.
+type kepIndex struct {
+	finder *keps.EnhancementFinder
+	byFile map[string]*keps.Proposal
+}
+
+func newKEPIndex(finder *keps.EnhancementFinder, proposals keps.Proposals) *kepIndex {
+	ix := &kepIndex{finder: finder, byFile: map[string]*keps.Proposal{}}
+	for _, p := range proposals {
+		ix.byFile[p.Filename] = p
+	}
+	return ix
+}
+
+// proposals returns the KEPs in the index ordered by filename.
+func (ix *kepIndex) proposals() keps.Proposals {
+	out := make(keps.Proposals, 0, len(ix.byFile))
+	for _, p := range ix.byFile {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Filename < out[j].Filename })
+	return out
+}
+
+// update re-parses the KEPs at or below paths and returns how they changed.
+func (ix *kepIndex) update(paths []string) []change {
+	parsed := map[string]*keps.Proposal{}
+	removed := map[string]bool{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			// Anything indexed below a directory that changed or went away
+			// may have gone too.
+			for filename := range ix.byFile {
+				if filename == path || strings.HasPrefix(filename, path+string(filepath.Separator)) {
+					removed[filename] = true
+				}
+			}
+		}
+		if err != nil {
+			continue
+		}
+		filepath.Walk(path, func(filename string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || ix.finder.Skip(info.Name()) {
+				return nil
+			}
+			if p, err := ix.finder.ParseFile(filename); err == nil {
+				parsed[filename] = p
+				delete(removed, filename)
+			}
+			return nil
+		})
+	}
+
+	var changes []change
+	for filename := range removed {
+		old := ix.byFile[filename]
+		delete(ix.byFile, filename)
+		changes = append(changes, change{Filename: filename, Title: old.Title, Kind: changeRemoved, New: []problem{}, Fixed: problems(old.Error)})
+	}
+	for filename, p := range parsed {
+		kind, before := changeAdded, []problem{}
+		if old, ok := ix.byFile[filename]; ok {
+			kind, before = changeChanged, problems(old.Error)
+		}
+		c := diffProblems(before, problems(p.Error))
+		c.Filename, c.Title, c.Kind = filename, p.Title, kind
+		ix.byFile[filename] = p
+		changes = append(changes, c)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Filename < changes[j].Filename })
+	return changes
+}
+
+// diffProblems compares the problems before and after a change.
+func diffProblems(before, after []problem) change {
+	key := func(p problem) string { return p.Code + "\x00" + p.Message }
+	remaining := map[string]int{}
+	for _, p := range before {
+		remaining[key(p)]++
+	}
+	c := change{New: []problem{}, Fixed: []problem{}, Problems: len(after)}
+	for _, p := range after {
+		if remaining[key(p)] > 0 {
+			remaining[key(p)]--
+			continue
+		}
+		c.New = append(c.New, p)
+	}
+	for _, p := range before {
+		if remaining[key(p)] > 0 {
+			remaining[key(p)]--
+			c.Fixed = append(c.Fixed, p)
+		}
+	}
+	return c
+}
+
+// watchKEPs updates the index with every batch of changes the watcher
+// reports and passes them to onChange, until the watcher is closed.
+func watchKEPs(w watcher, ix *kepIndex, onChange func([]change), onError func(error)) {
+	pending := map[string]bool{}
+	// flush fires once the changes pause and deadline once the first pending
+	// change has waited maxDebounce, whichever comes first.
+	var flush, deadline <-chan time.Time
+	update := func() {
+		paths := make([]string, 0, len(pending))
+		for path := range pending {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		pending, flush, deadline = map[string]bool{}, nil, nil
+		if changes := ix.update(paths); len(changes) > 0 {
+			onChange(changes)
+		}
+	}
+	for {
+		select {
+		case path, ok := <-w.Events():
+			if !ok {
+				return
+			}
+			pending[path] = true
+			flush = time.After(debounce)
+			if deadline == nil {
+				deadline = time.After(maxDebounce)
+			}
+		case err, ok := <-w.Errors():
+			if ok {
+				onError(err)
+			}
+		case <-flush:
+			update()
+		case <-deadline:
+			update()
+		}
+	}
+}
+
+// writeChanges prints each change with the problems it introduced and fixed.
+func writeChanges(w io.Writer, changes []change) {
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s %s: %d problem(s)\n", c.Filename, c.Kind, c.Problems)
+		for _, p := range c.New {
+			fmt.Fprintf(w, "  + %s\n", describe(p))
+		}
+		for _, p := range c.Fixed {
+			fmt.Fprintf(w, "  - %s\n", describe(p))
+		}
+	}
+}
+
+func describe(p problem) string {
+	if p.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s (%s)", p.Line, p.Severity, p.Message, p.Code)
+	}
+	return fmt.Sprintf("%s: %s (%s)", p.Severity, p.Message, p.Code)
+}
+
+// watch re-parses KEPs as they change and prints what changed.
+func watch(args []string) error {
+	configuration := &config{}
+	fs := configuration.flags("watch")
+	format := fs.String("format", "text", "output format: text|json")
+	options := &watchOptions{}
+	options.flags(fs)
+	fs.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		return errors.Errorf("unknown format %q, must be one of json|text", *format)
+	}
+	proposals, rules, err := configuration.proposals()
+	if err != nil {
+		return err
+	}
+	w, err := options.watcher(configuration.root)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	fmt.Fprintf(os.Stderr, "Watching %d KEPs in %s\n", len(proposals), configuration.root)
+
+	enc := json.NewEncoder(os.Stdout)
+	ix := newKEPIndex(configuration.finder(rules), proposals)
+	watchKEPs(w, ix, func(changes []change) {
+		if *format == "json" {
+			for _, c := range changes {
+				enc.Encode(c)
+			}
+			return
+		}
+		writeChanges(os.Stdout, changes)
+	}, func(err error) {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	})
+	return nil
+}
+
+// fileState is what the poll watcher compares to find changed files.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// pollWatcher finds changes by scanning a directory on an interval.
+type pollWatcher struct {
+	root   string
+	events chan string
+	errs   chan error
+	done   chan struct{}
+}
+
+func newPollWatcher(root string, interval time.Duration) (*pollWatcher, error) {
+	w := &pollWatcher{root: root, events: make(chan string), errs: make(chan error), done: make(chan struct{})}
+	files, err := w.scan()
+	if err != nil {
+		return nil, err
+	}
+	go w.run(files, interval)
+	return w, nil
+}
+
+func (w *pollWatcher) Events() <-chan string { return w.events }
+func (w *pollWatcher) Errors() <-chan error  { return w.errs }
+
+func (w *pollWatcher) Close() error {
+	close(w.done)
+	return nil
+}
+
+func (w *pollWatcher) scan() (map[string]fileState, error) {
+	files := map[string]fileState{}
+	err := filepath.Walk(w.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			files[path] = fileState{info.ModTime(), info.Size()}
+		}
+		return nil
+	})
+	return files, errors.WithStack(err)
+}
+
+func (w *pollWatcher) run(files map[string]fileState, interval time.Duration) {
+	defer close(w.events)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		current, err := w.scan()
+		if err != nil {
+			select {
+			case w.errs <- err:
+			case <-w.done:
+				return
+			}
+			continue
+		}
+		var changed []string
+		for path, state := range current {
+			if old, ok := files[path]; !ok || !old.modTime.Equal(state.modTime) || old.size != state.size {
+				changed = append(changed, path)
+			}
+		}
+		for path := range files {
+			if _, ok := current[path]; !ok {
+				changed = append(changed, path)
+			}
+		}
+		files = current
+		sort.Strings(changed)
+		for _, path := range changed {
+			select {
+			case w.events <- path:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
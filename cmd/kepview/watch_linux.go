@@ -0,0 +1,168 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// notifyMask are the inotify events that can change a KEP.
+const notifyMask = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_DELETE | syscall.IN_DELETE_SELF
+
+// notifyWatcher watches every directory below a root with inotify.
+type notifyWatcher struct {
+	root   string
+	fd     int
+	file   *os.File
+	events chan string
+	errs   chan error
+	done   chan struct{}
+
+	mu   sync.Mutex
+	dirs map[int]string
+}
+
+func newNotifyWatcher(root string) (watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, errors.Wrap(err, "inotify")
+	}
+	w := &notifyWatcher{
+		root: root,
+		fd:   fd,
+		// A non-blocking file is read through the runtime poller, so Close
+		// interrupts a pending Read.
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		events: make(chan string),
+		errs:   make(chan error),
+		done:   make(chan struct{}),
+		dirs:   map[int]string{},
+	}
+	if err := w.addTree(root); err != nil {
+		w.file.Close()
+		return nil, err
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *notifyWatcher) Events() <-chan string { return w.events }
+func (w *notifyWatcher) Errors() <-chan error  { return w.errs }
+
+func (w *notifyWatcher) Close() error {
+	close(w.done)
+	return errors.WithStack(w.file.Close())
+}
+
+// addTree watches dir and every directory below it.
+func (w *notifyWatcher) addTree(dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		wd, err := syscall.InotifyAddWatch(w.fd, path, notifyMask)
+		if err != nil {
+			return errors.Wrapf(err, "watching %s", path)
+		}
+		w.mu.Lock()
+		w.dirs[wd] = path
+		w.mu.Unlock()
+		return nil
+	})
+}
+
+func (w *notifyWatcher) run() {
+	defer close(w.events)
+	buf := make([]byte, 4096*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			select {
+			case <-w.done:
+			case w.errs <- errors.WithStack(err):
+			}
+			return
+		}
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			offset = nameStart + int(event.Len)
+			if !w.handle(event, buf[nameStart:offset]) {
+				return
+			}
+		}
+	}
+}
+
+// handle reports the path an event is about, returning false once the
+// watcher is closed.
+func (w *notifyWatcher) handle(event *syscall.InotifyEvent, name []byte) bool {
+	if event.Mask&syscall.IN_Q_OVERFLOW != 0 {
+		// Events were lost, so anything may have changed.
+		return w.send(w.root)
+	}
+	w.mu.Lock()
+	dir, ok := w.dirs[int(event.Wd)]
+	if event.Mask&syscall.IN_IGNORED != 0 {
+		delete(w.dirs, int(event.Wd))
+	}
+	w.mu.Unlock()
+	if !ok || event.Mask&syscall.IN_IGNORED != 0 {
+		return true
+	}
+	path := dir
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+	if len(name) > 0 {
+		path = filepath.Join(dir, string(name))
+	}
+	if event.Mask&syscall.IN_ISDIR != 0 && event.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+		// Files may have been added to a new directory before it was
+		// watched, which reporting the directory covers.
+		if err := w.addTree(path); err != nil {
+			select {
+			case <-w.done:
+				return false
+			case w.errs <- err:
+			}
+		}
+	}
+	return w.send(path)
+}
+
+func (w *notifyWatcher) send(path string) bool {
+	select {
+	case <-w.done:
+		return false
+	case w.events <- path:
+		return true
+	}
+}
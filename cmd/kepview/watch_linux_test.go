@@ -0,0 +1,55 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNotifyWatcher(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+	w, err := newNotifyWatcher(root)
+	if err != nil {
+		t.Skipf("inotify is not available: %v", err)
+	}
+	defer w.Close()
+
+	a := filepath.Join(root, "0001-a.md")
+	writeFile(t, a, "a")
+	expectEvent(t, w, a)
+
+	// New directories are reported and then watched.
+	dir := filepath.Join(root, "sig-node")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, dir)
+	b := filepath.Join(dir, "0002-b.md")
+	writeFile(t, b, "b")
+	expectEvent(t, w, b)
+
+	if err := os.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, a)
+}
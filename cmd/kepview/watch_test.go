@@ -0,0 +1,221 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+func writeFile(t *testing.T, path, contents string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	root, err := ioutil.TempDir("", "kepview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root, func() { os.RemoveAll(root) }
+}
+
+func testIndex(t *testing.T, root string) *kepIndex {
+	finder := keps.NewEnhancementFinder(keps.WithParser(&keps.Parser{Config: validations.DefaultConfig()}))
+	out := &keps.Proposals{}
+	if err := filepath.Walk(root, finder.Find(out)); err != nil {
+		t.Fatal(err)
+	}
+	return newKEPIndex(finder, *out)
+}
+
+func TestKEPIndexUpdate(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+	a := filepath.Join(root, "sig-node", "0001-a.md")
+	b := filepath.Join(root, "sig-network", "0002-b.md")
+	writeFile(t, a, "---\ntitle: A\nauthors: someone\n---\n")
+	writeFile(t, b, "---\ntitle: B\n---\n")
+	ix := testIndex(t, root)
+
+	// Fixing a KEP reports the problems that went away.
+	writeFile(t, a, "---\ntitle: A\nauthors:\n  - \"@a\"\n---\n")
+	changes := ix.update([]string{a})
+	if len(changes) != 1 || changes[0].Kind != changeChanged || len(changes[0].Fixed) != 1 || changes[0].Fixed[0].Code != "value-must-be-list-of-strings" {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+
+	// Files below a new directory are added and skipped files are ignored.
+	c := filepath.Join(root, "sig-node", "0003-c", "0003-c.md")
+	writeFile(t, c, "---\ntitle: C\n---\n")
+	writeFile(t, filepath.Join(root, "sig-node", "0003-c", "README.md"), "# not a KEP\n")
+	changes = ix.update([]string{filepath.Dir(c)})
+	if len(changes) != 1 || changes[0].Kind != changeAdded || changes[0].Title != "C" {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+
+	// Removing a directory removes the KEPs below it.
+	if err := os.RemoveAll(filepath.Join(root, "sig-network")); err != nil {
+		t.Fatal(err)
+	}
+	changes = ix.update([]string{filepath.Join(root, "sig-network")})
+	if len(changes) != 1 || changes[0].Kind != changeRemoved || changes[0].Filename != b {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+	if proposals := ix.proposals(); len(proposals) != 2 || proposals[0].Filename != a || proposals[1].Filename != c {
+		t.Fatalf("unexpected index %v", proposals)
+	}
+}
+
+func TestDiffProblems(t *testing.T) {
+	before := []problem{
+		{Line: 2, Code: "a", Message: "moved"},
+		{Line: 3, Code: "b", Message: "fixed"},
+	}
+	after := []problem{
+		{Line: 5, Code: "a", Message: "moved"},
+		{Line: 6, Code: "c", Message: "new"},
+	}
+	c := diffProblems(before, after)
+	if len(c.New) != 1 || c.New[0].Code != "c" || len(c.Fixed) != 1 || c.Fixed[0].Code != "b" || c.Problems != 2 {
+		t.Fatalf("unexpected diff %+v", c)
+	}
+}
+
+// fakeWatcher reports the paths sent to it.
+type fakeWatcher struct {
+	events chan string
+	errs   chan error
+}
+
+func (w *fakeWatcher) Events() <-chan string { return w.events }
+func (w *fakeWatcher) Errors() <-chan error  { return w.errs }
+func (w *fakeWatcher) Close() error {
+	close(w.events)
+	return nil
+}
+
+func TestWatchKEPs(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+	ix := testIndex(t, root)
+	w := &fakeWatcher{events: make(chan string), errs: make(chan error)}
+	batches := make(chan []change)
+	done := make(chan struct{})
+	go func() {
+		watchKEPs(w, ix, func(changes []change) { batches <- changes }, func(err error) { t.Error(err) })
+		close(done)
+	}()
+
+	a := filepath.Join(root, "0001-a.md")
+	writeFile(t, a, "---\ntitle: A\n---\n")
+	// Several events for the same file are re-parsed once.
+	w.events <- a
+	w.events <- a
+	select {
+	case changes := <-batches:
+		if len(changes) != 1 || changes[0].Filename != a || changes[0].Kind != changeAdded {
+			t.Fatalf("unexpected changes %+v", changes)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for changes")
+	}
+	w.Close()
+	<-done
+}
+
+func TestWatchKEPsMaxDebounce(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+	ix := testIndex(t, root)
+	w := &fakeWatcher{events: make(chan string), errs: make(chan error)}
+	batches := make(chan []change, 1)
+	done := make(chan struct{})
+	go func() {
+		watchKEPs(w, ix, func(changes []change) { batches <- changes }, func(err error) { t.Error(err) })
+		close(done)
+	}()
+
+	a := filepath.Join(root, "0001-a.md")
+	writeFile(t, a, "---\ntitle: A\n---\n")
+	// Changes arriving faster than the debounce still get re-parsed.
+	w.events <- a
+	ticker := time.NewTicker(debounce / 2)
+	defer ticker.Stop()
+	timeout := time.After(5 * maxDebounce)
+	for {
+		select {
+		case changes := <-batches:
+			if len(changes) != 1 || changes[0].Filename != a {
+				t.Fatalf("unexpected changes %+v", changes)
+			}
+			w.Close()
+			<-done
+			return
+		case <-ticker.C:
+			w.events <- filepath.Join(root, "busy")
+		case <-timeout:
+			t.Fatal("timed out waiting for changes")
+		}
+	}
+}
+
+func TestPollWatcher(t *testing.T) {
+	root, cleanup := tempDir(t)
+	defer cleanup()
+	a := filepath.Join(root, "0001-a.md")
+	writeFile(t, a, "a")
+	w, err := newPollWatcher(root, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	b := filepath.Join(root, "sig-node", "0002-b.md")
+	writeFile(t, b, "b")
+	expectEvent(t, w, b)
+	if err := os.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, a)
+}
+
+func expectEvent(t *testing.T, w watcher, path string) {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case got := <-w.Events():
+			if got == path {
+				return
+			}
+		case err := <-w.Errors():
+			t.Fatal(err)
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s", path)
+		}
+	}
+}
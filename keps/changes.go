@@ -0,0 +1,49 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps
+
+import (
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+// ValidateChanges compares the proposal with an earlier version of the same
+// KEP. The status must follow the KEP lifecycle and, if anything changed,
+// last-updated must not go backwards. The returned error is a
+// validations.ErrorList.
+func (p *Proposal) ValidateChanges(base *Proposal) error {
+	if p.Contents == base.Contents {
+		return nil
+	}
+	position := func(string) (int, int) { return 1, 0 }
+	if p.FrontMatter != nil {
+		position = keyPosition(p.FrontMatter.Metadata, p.FrontMatter.MetadataLine())
+	}
+
+	var errs validations.ErrorList
+	if err := validations.ValidateStatusTransition(base.Status, p.Status); err != nil {
+		line, column := position("status")
+		errs = append(errs, &validations.Error{Line: line, Column: column, Err: err})
+	}
+	if err := validations.ValidateLastUpdated(base.LastUpdated, p.LastUpdated); err != nil {
+		line, column := position("last-updated")
+		errs = append(errs, &validations.Error{Line: line, Column: column, Err: err})
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+func TestValidateChanges(t *testing.T) {
+	base := "---\ntitle: test\nstatus: provisional\nlast-updated: 2019-01-10\n---\n"
+	testcases := []struct {
+		name     string
+		kep      string
+		expected []string
+	}{
+		{"unchanged", base, nil},
+		{"updated the same day", strings.Replace(base, "title: test", "title: renamed", 1), nil},
+		{"updated later", strings.Replace(base, "2019-01-10", "2019-02-01", 1), nil},
+		{"date went backwards", strings.Replace(base, "2019-01-10", "2019-01-09", 1), []string{"last-updated-not-bumped"}},
+		{"status went backwards", strings.Replace(base, "provisional", "implemented", 1), []string{"invalid-status-transition"}},
+	}
+	parse := func(s string) *keps.Proposal { return (&keps.Parser{}).Parse(strings.NewReader(s)) }
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := parse(tc.kep).ValidateChanges(parse(base))
+			errs, _ := err.(validations.ErrorList)
+			if err != nil && errs == nil {
+				t.Fatalf("expected a list of errors but got %v", err)
+			}
+			codes := []string{}
+			for _, e := range errs {
+				codes = append(codes, e.Code())
+			}
+			if strings.Join(codes, " ") != strings.Join(tc.expected, " ") {
+				t.Fatalf("expected %v but got %v", tc.expected, codes)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps
+
+import (
+	"strings"
+)
+
+// FilenameFilter matches the names of files that are not KEPs.
+type FilenameFilter struct {
+	Match func(string) bool
+	Name  string
+}
+
+// Filter returns true if the file should be skipped.
+func (f FilenameFilter) Filter(in string) bool {
+	return f.Match(in)
+}
+
+func (f FilenameFilter) String() string {
+	return f.Name
+}
+
+// DefaultFilenameFilters skips the files in the enhancements repo that live
+// next to KEPs but are not KEPs themselves.
+func DefaultFilenameFilters() []FilenameFilter {
+	return []FilenameFilter{
+		{
+			func(in string) bool {
+				return strings.HasPrefix(in, "README")
+			},
+			"Ignore READMEs",
+		},
+		{
+			func(in string) bool {
+				return !strings.HasSuffix(in, ".md")
+			},
+			"Ignore non markdown files",
+		},
+		{
+			func(in string) bool {
+				return strings.HasSuffix(in, "template.md")
+			},
+			"Ignore template files",
+		},
+		{
+			func(in string) bool {
+				return in == "kep-faq.md"
+			},
+			"Ignore the kep faq",
+		},
+		{
+			func(in string) bool {
+				return in == "0023-documentation-for-images.md"
+			},
+			"Ignore the non-kep file",
+		},
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// ProposalParser parses a KEP.
+type ProposalParser interface {
+	Parse(io.Reader) *Proposal
+}
+
+// Opener opens the files an EnhancementFinder parses.
+type Opener interface {
+	Open(string) (*os.File, error)
+}
+
+// Logger receives an EnhancementFinder's debug logs.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+}
+
+// Filter returns true for the names of files that aren't KEPs.
+type Filter interface {
+	Filter(string) bool
+}
+
+type fileOpener struct{}
+
+func (fileOpener) Open(path string) (*os.File, error) {
+	return os.Open(path)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debugf(format string, args ...interface{}) {}
+
+// EnhancementFinder can filter out non-enhancement-like filenames in
+// addition to parsing the KEPs and reporting failure statuses
+type EnhancementFinder struct {
+	opener          Opener
+	parser          ProposalParser
+	filenameFilters []Filter
+	log             Logger
+}
+
+// NewEnhancementFinder returns a reasonably configured EnhancementFinder
+func NewEnhancementFinder(opts ...FinderOption) *EnhancementFinder {
+	ef := &EnhancementFinder{
+		opener:          fileOpener{},
+		parser:          &Parser{},
+		log:             nopLogger{},
+		filenameFilters: defaultFilters(),
+	}
+	for _, opt := range opts {
+		opt(ef)
+	}
+	return ef
+}
+
+func defaultFilters() []Filter {
+	var filters []Filter
+	for _, f := range DefaultFilenameFilters() {
+		filters = append(filters, f)
+	}
+	return filters
+}
+
+// FinderOption configures an EnhancementFinder.
+type FinderOption func(*EnhancementFinder)
+
+// WithOpener sets the object that opens files
+func WithOpener(opener Opener) FinderOption {
+	return func(e *EnhancementFinder) { e.opener = opener }
+}
+
+// WithParser sets the parser that prases KEPs
+func WithParser(parser ProposalParser) FinderOption {
+	return func(e *EnhancementFinder) { e.parser = parser }
+}
+
+// WithLog defines the logger for the finder
+func WithLog(log Logger) FinderOption {
+	return func(e *EnhancementFinder) { e.log = log }
+}
+
+// WithFilenameFilters sets the list of filters the filenames must pass
+func WithFilenameFilters(filters ...Filter) FinderOption {
+	return func(e *EnhancementFinder) { e.filenameFilters = filters }
+}
+
+// Find returns a function that filters out filenames and prases a valid KEP file.
+// Is also a WalkFunc.
+func (e *EnhancementFinder) Find(out *Proposals) filepath.WalkFunc {
+	return func(path string, info os.FileInfo, err error) error {
+		if path == "" {
+			return nil
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if info.IsDir() || e.Skip(info.Name()) {
+			return nil
+		}
+		kep, err := e.ParseFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "filename: %v", info.Name())
+		}
+		out.AddProposal(kep)
+		return nil
+	}
+}
+
+// Skip returns true if a filename doesn't pass the filters.
+func (e *EnhancementFinder) Skip(name string) bool {
+	for _, f := range e.filenameFilters {
+		if f.Filter(name) {
+			e.log.Debugf("Skipping %q due to filename filter: %v\n", name, f)
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFile opens and parses the KEP at path.
+func (e *EnhancementFinder) ParseFile(path string) (*Proposal, error) {
+	file, err := e.opener.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	// Parse always returns a proposal even on failure.
+	kep := e.parser.Parse(file)
+	kep.Filename = path
+	return kep, nil
+}
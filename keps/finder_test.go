@@ -0,0 +1,160 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps_test
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chuckha/kepview/keps"
+)
+
+type info struct {
+	name string
+}
+
+func (i *info) Name() string       { return i.name }
+func (i *info) Size() int64        { return 0 }
+func (i *info) Mode() os.FileMode  { return os.FileMode(100) }
+func (i *info) ModTime() time.Time { return time.Date(2019, 4, 20, 0, 0, 0, 0, nil) }
+func (i *info) IsDir() bool        { return false }
+func (i *info) Sys() interface{}   { return struct{}{} }
+
+type myparser struct {
+	proposal *keps.Proposal
+}
+
+func (p *myparser) Parse(reader io.Reader) *keps.Proposal {
+	return p.proposal
+}
+
+type myopener struct {
+	file *os.File
+}
+
+func (o *myopener) Open(path string) (*os.File, error) {
+	return o.file, nil
+}
+
+type mylogger struct{}
+
+func (l *mylogger) Debugf(format string, args ...interface{}) {}
+
+func defaultTestEnhancementFinder(opts ...keps.FinderOption) *keps.EnhancementFinder {
+	opts = append([]keps.FinderOption{
+		keps.WithOpener(&myopener{}),
+		keps.WithParser(&myparser{}),
+		keps.WithLog(&mylogger{}),
+	}, opts...)
+	return keps.NewEnhancementFinder(opts...)
+}
+
+func TestFindEnhancementsIgnores(t *testing.T) {
+	testcases := []struct {
+		name     string
+		filename string
+	}{
+		{
+			"a basic readme",
+			"README.md",
+		},
+		{
+			"owners file",
+			"OWNERS",
+		},
+		{
+			"images",
+			"something.png",
+		},
+		{
+			"ignore templates",
+			"myfavorite-template.md",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ef := defaultTestEnhancementFinder()
+			out := &keps.Proposals{}
+			fe := ef.Find(out)
+			i := &info{tc.filename}
+			if err := fe("test", i, nil); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if len(*out) != 0 {
+				t.Fatalf("Did not expect to find anything but found %v", out)
+			}
+		})
+	}
+}
+
+func TestEnhancementFinder(t *testing.T) {
+	testcases := []struct {
+		name     string
+		filename string
+	}{
+		{
+			"simple test",
+			"my-simple-test.md",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ef := defaultTestEnhancementFinder(keps.WithParser(&myparser{&keps.Proposal{}}))
+			out := &keps.Proposals{}
+			fe := ef.Find(out)
+			i := &info{tc.filename}
+			if err := fe("test", i, nil); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if len(*out) != 1 {
+				t.Fatalf("Expected 1 item but found: %v", out)
+			}
+			if (*out)[0].Filename != "test" {
+				t.Fatalf("expected proposal to have a filename of %q but had %q", tc.filename, (*out)[0].Filename)
+			}
+		})
+	}
+}
+
+func TestEnhancementFinderWalk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"sig-foo/0001-a.md", "sig-foo/README.md", "sig-foo/OWNERS", "sig-bar/kep.md/0002-b.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte("---\ntitle: "+filepath.Base(name)+"\n---\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out keps.Proposals
+	if err := filepath.Walk(dir, keps.NewEnhancementFinder().Find(&out)); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	// Directories are walked into, not parsed, even when named like a KEP.
+	if len(out) != 2 || out[0].Title != "0002-b.md" || out[1].Title != "0001-a.md" {
+		t.Fatalf("expected the two KEPs but got %v", out)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNoFrontMatter is returned when a file has no front-matter delimiter.
+var ErrNoFrontMatter = errors.New("no front matter found")
+
+// ErrUnterminatedFrontMatter is returned when the opening delimiter is never closed.
+var ErrUnterminatedFrontMatter = errors.New("front matter is not terminated with ---")
+
+const delimiter = "---"
+
+// FrontMatter is a KEP file split around its YAML metadata block.
+// Concatenating Header, Opening, Metadata, Closing and Body gives back the
+// original file byte for byte.
+type FrontMatter struct {
+	// Header is anything above the opening delimiter.
+	Header []byte
+	// Opening and Closing are the delimiter lines including their line endings.
+	Opening  []byte
+	Metadata []byte
+	Closing  []byte
+	Body     []byte
+
+	// MetadataStart and MetadataEnd are the byte offsets of the metadata in
+	// the original file and BodyStart is the offset of the body.
+	MetadataStart int
+	MetadataEnd   int
+	BodyStart     int
+}
+
+// SplitFrontMatter finds the first pair of lines that are exactly "---" and
+// splits the content around them. Lines that merely contain "---", such as
+// table separators or horizontal rules with trailing text, are not delimiters.
+// When there is no front matter at all the returned FrontMatter holds the
+// whole content as its body along with ErrNoFrontMatter.
+func SplitFrontMatter(content []byte) (*FrontMatter, error) {
+	fm := &FrontMatter{Body: content}
+	opening, openingEnd := -1, -1
+	offset := 0
+	for offset < len(content) {
+		end := bytes.IndexByte(content[offset:], '\n')
+		if end < 0 {
+			end = len(content)
+		} else {
+			end += offset + 1
+		}
+		if isDelimiter(content[offset:end]) {
+			if opening < 0 {
+				opening, openingEnd = offset, end
+			} else {
+				fm.Header = content[:opening]
+				fm.Opening = content[opening:openingEnd]
+				fm.Metadata = content[openingEnd:offset]
+				fm.Closing = content[offset:end]
+				fm.Body = content[end:]
+				fm.MetadataStart = openingEnd
+				fm.MetadataEnd = offset
+				fm.BodyStart = end
+				return fm, nil
+			}
+		}
+		offset = end
+	}
+	if opening < 0 {
+		return fm, ErrNoFrontMatter
+	}
+	return fm, ErrUnterminatedFrontMatter
+}
+
+func isDelimiter(line []byte) bool {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	return string(line) == delimiter
+}
+
+// MetadataLine returns the 1-based line number of the first metadata line.
+func (f *FrontMatter) MetadataLine() int {
+	return bytes.Count(f.Header, []byte("\n")) + 2
+}
+
+// BodyLine returns the 1-based line number of the first body line.
+func (f *FrontMatter) BodyLine() int {
+	if len(f.Opening) == 0 {
+		return 1
+	}
+	return f.MetadataLine() + bytes.Count(f.Metadata, []byte("\n")) + 1
+}
+
+// Bytes reassembles the file. Replacing Metadata and calling Bytes rewrites the
+// metadata while keeping the delimiters and body exactly as they were.
+func (f *FrontMatter) Bytes() []byte {
+	var buf bytes.Buffer
+	buf.Write(f.Header)
+	buf.Write(f.Opening)
+	buf.Write(f.Metadata)
+	if len(f.Metadata) > 0 && f.Metadata[len(f.Metadata)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	buf.Write(f.Closing)
+	buf.Write(f.Body)
+	return buf.Bytes()
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps_test
+
+import (
+	"testing"
+
+	"github.com/chuckha/kepview/keps"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	testcases := []struct {
+		name          string
+		content       string
+		header        string
+		metadata      string
+		body          string
+		metadataLine  int
+		bodyLine      int
+		expectedError error
+	}{
+		{
+			"simple front matter",
+			"---\ntitle: test\n---\n# Title\n",
+			"",
+			"title: test\n",
+			"# Title\n",
+			2,
+			4,
+			nil,
+		},
+		{
+			"header above the front matter",
+			"<!-- comment -->\n---\ntitle: test\nstatus: provisional\n---\nbody",
+			"<!-- comment -->\n",
+			"title: test\nstatus: provisional\n",
+			"body",
+			3,
+			6,
+			nil,
+		},
+		{
+			"lines containing dashes are not delimiters",
+			"---\ntitle: \"a --- b\"\n--- not a delimiter\n---\n| a | b |\n|---|---|\n---\n",
+			"",
+			"title: \"a --- b\"\n--- not a delimiter\n",
+			"| a | b |\n|---|---|\n---\n",
+			2,
+			5,
+			nil,
+		},
+		{
+			"code fences are not delimiters",
+			"---\ntitle: test\n```\nbody\n",
+			"",
+			"",
+			"---\ntitle: test\n```\nbody\n",
+			2,
+			1,
+			keps.ErrUnterminatedFrontMatter,
+		},
+		{
+			"no front matter",
+			"# Title\n",
+			"",
+			"",
+			"# Title\n",
+			2,
+			1,
+			keps.ErrNoFrontMatter,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fm, err := keps.SplitFrontMatter([]byte(tc.content))
+			if err != tc.expectedError {
+				t.Fatalf("expected error %v but got %v", tc.expectedError, err)
+			}
+			if string(fm.Header) != tc.header {
+				t.Fatalf("expected header %q but got %q", tc.header, fm.Header)
+			}
+			if string(fm.Metadata) != tc.metadata {
+				t.Fatalf("expected metadata %q but got %q", tc.metadata, fm.Metadata)
+			}
+			if string(fm.Body) != tc.body {
+				t.Fatalf("expected body %q but got %q", tc.body, fm.Body)
+			}
+			if string(fm.Bytes()) != tc.content {
+				t.Fatalf("expected %q to round trip but got %q", tc.content, fm.Bytes())
+			}
+			if err != nil {
+				return
+			}
+			if tc.content[fm.MetadataStart:fm.MetadataEnd] != tc.metadata {
+				t.Fatalf("metadata offsets %d:%d do not match the metadata", fm.MetadataStart, fm.MetadataEnd)
+			}
+			if tc.content[fm.BodyStart:] != tc.body {
+				t.Fatalf("body offset %d does not match the body", fm.BodyStart)
+			}
+			if fm.MetadataLine() != tc.metadataLine {
+				t.Fatalf("expected metadata to start on line %d but got %d", tc.metadataLine, fm.MetadataLine())
+			}
+			if fm.BodyLine() != tc.bodyLine {
+				t.Fatalf("expected body to start on line %d but got %d", tc.bodyLine, fm.BodyLine())
+			}
+		})
+	}
+}
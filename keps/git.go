@@ -0,0 +1,43 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// LastCommitTime asks git when the file at path was last committed. git runs
+// in the file's directory, so path may be in any checkout.
+func LastCommitTime(path string) (time.Time, error) {
+	cmd := exec.Command("git", "log", "-1", "--format=%aI", "--", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	out, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, errors.WithStack(err)
+	}
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		return time.Time{}, errors.Errorf("%v has no commits", path)
+	}
+	t, err := time.Parse(time.RFC3339, string(out))
+	return t, errors.WithStack(err)
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of relationship between KEPs.
+const (
+	EdgeSeeAlso      = "see-also"
+	EdgeReplaces     = "replaces"
+	EdgeSupersededBy = "superseded-by"
+)
+
+// Node is a KEP in a Graph. KEPs that are referred to but weren't parsed
+// have no Proposal.
+type Node struct {
+	// ID is the KEP's path relative to the keps directory without .md, or
+	// the reference itself when it couldn't be resolved.
+	ID       string    `json:"id"`
+	Title    string    `json:"title,omitempty"`
+	SIG      string    `json:"sig,omitempty"`
+	Status   string    `json:"status,omitempty"`
+	Proposal *Proposal `json:"-"`
+}
+
+// Edge is a relationship from one KEP to another.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// Graph is how a set of KEPs refer to each other.
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []Edge  `json:"edges"`
+
+	byID map[string]*Node
+	// refs maps the ways a parsed KEP can be referred to onto its node. KEP
+	// numbers are prefixed with #.
+	refs map[string]*Node
+}
+
+var (
+	markdownLinkRe = regexp.MustCompile(`\]\(([^)\s]+)\)`)
+	kepNumberRe    = regexp.MustCompile(`^(?i:kep-?|#)?0*([0-9]+)$`)
+	leadingNumRe   = regexp.MustCompile(`^0*([0-9]+)-`)
+)
+
+// BuildGraph connects the proposals through their see-also, replaces and
+// superseded-by references. root is the keps directory the proposals'
+// filenames are in.
+func BuildGraph(proposals Proposals, root string) *Graph {
+	g := &Graph{Nodes: []*Node{}, Edges: []Edge{}, byID: map[string]*Node{}, refs: map[string]*Node{}}
+	for _, p := range proposals {
+		n := &Node{ID: kepID(p.Filename, root), Title: p.Title, SIG: p.OwningSIG, Status: p.Status, Proposal: p}
+		g.add(n)
+		base := path.Base(n.ID)
+		keys := []string{n.ID, base}
+		if m := leadingNumRe.FindStringSubmatch(base); m != nil {
+			keys = append(keys, "#"+m[1])
+		}
+		for _, key := range keys {
+			if _, ok := g.refs[key]; !ok {
+				g.refs[key] = n
+			}
+		}
+	}
+
+	for _, n := range append([]*Node(nil), g.Nodes...) {
+		p := n.Proposal
+		for _, refs := range []struct {
+			kind string
+			refs []string
+		}{
+			{EdgeSeeAlso, p.SeeAlso},
+			{EdgeReplaces, p.Replaces},
+			{EdgeSupersededBy, p.SupersededBy},
+		} {
+			for _, ref := range refs.refs {
+				ref = cleanRef(ref)
+				if ref == "" {
+					continue
+				}
+				target := g.Node(ref)
+				if target == nil {
+					// Keep references to KEPs that weren't parsed.
+					target = &Node{ID: ref}
+					g.add(target)
+				}
+				if target != n {
+					g.Edges = append(g.Edges, Edge{From: n.ID, To: target.ID, Kind: refs.kind})
+				}
+			}
+		}
+	}
+	g.sort()
+	return g
+}
+
+// kepID returns the path of a KEP file relative to root without .md.
+func kepID(filename, root string) string {
+	rel, err := filepath.Rel(root, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filename
+	}
+	return strings.TrimSuffix(filepath.ToSlash(rel), ".md")
+}
+
+// cleanRef returns the target of a markdown link reference without its
+// fragment, or nothing for placeholders.
+func cleanRef(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if m := markdownLinkRe.FindStringSubmatch(ref); m != nil {
+		ref = m[1]
+	}
+	if i := strings.IndexAny(ref, "#?"); i > 0 {
+		ref = ref[:i]
+	}
+	if strings.EqualFold(ref, "TBD") || strings.EqualFold(ref, "N/A") {
+		return ""
+	}
+	return ref
+}
+
+// Node returns the node a reference such as /keps/sig-node/0014-foo.md,
+// 0014-foo or KEP-14 points to, or nil.
+func (g *Graph) Node(ref string) *Node {
+	if n, ok := g.byID[ref]; ok {
+		return n
+	}
+	key := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(cleanRef(ref), "./"), "/"), ".md")
+	key = strings.TrimPrefix(key, "keps/")
+	if n, ok := g.refs[key]; ok {
+		return n
+	}
+	if n, ok := g.refs[path.Base(key)]; ok {
+		return n
+	}
+	// Issue URLs end in numbers too.
+	if m := kepNumberRe.FindStringSubmatch(key); m != nil && !strings.Contains(key, "://") {
+		return g.refs["#"+m[1]]
+	}
+	return nil
+}
+
+func (g *Graph) add(n *Node) {
+	if _, ok := g.byID[n.ID]; ok {
+		// Two files can't share an ID but keep them apart anyway.
+		n.ID += "#" + strconv.Itoa(len(g.Nodes))
+	}
+	g.byID[n.ID] = n
+	g.Nodes = append(g.Nodes, n)
+}
+
+func (g *Graph) sort() {
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Kind < b.Kind
+	})
+}
+
+// Subgraph returns the nodes keep accepts and the edges between them.
+func (g *Graph) Subgraph(keep func(*Node) bool) *Graph {
+	out := &Graph{Nodes: []*Node{}, Edges: []Edge{}, byID: map[string]*Node{}}
+	for _, n := range g.Nodes {
+		if keep(n) {
+			out.Nodes = append(out.Nodes, n)
+			out.byID[n.ID] = n
+		}
+	}
+	for _, e := range g.Edges {
+		if out.byID[e.From] != nil && out.byID[e.To] != nil {
+			out.Edges = append(out.Edges, e)
+		}
+	}
+	return out
+}
+
+// Neighbourhood returns the nodes within depth edges of root, following
+// edges in either direction. A depth of 0 or less has no limit.
+func (g *Graph) Neighbourhood(root *Node, depth int) map[*Node]bool {
+	adjacent := map[string][]string{}
+	for _, e := range g.Edges {
+		adjacent[e.From] = append(adjacent[e.From], e.To)
+		adjacent[e.To] = append(adjacent[e.To], e.From)
+	}
+	seen := map[*Node]bool{root: true}
+	frontier := []*Node{root}
+	for d := 0; len(frontier) > 0 && (depth <= 0 || d < depth); d++ {
+		var next []*Node
+		for _, n := range frontier {
+			for _, id := range adjacent[n.ID] {
+				m := g.byID[id]
+				if !seen[m] {
+					seen[m] = true
+					next = append(next, m)
+				}
+			}
+		}
+		frontier = next
+	}
+	return seen
+}
+
+// Adjacency maps each node ID to the IDs of the nodes it refers to.
+func (g *Graph) Adjacency() map[string][]string {
+	adjacency := map[string][]string{}
+	for _, n := range g.Nodes {
+		adjacency[n.ID] = []string{}
+	}
+	for _, e := range g.Edges {
+		adjacency[e.From] = append(adjacency[e.From], e.To)
+	}
+	return adjacency
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/chuckha/kepview/keps"
+)
+
+func testGraph() *keps.Graph {
+	root := filepath.Join("enhancements", "keps")
+	kep := func(name string) string { return filepath.Join(root, filepath.FromSlash(name)) }
+	return keps.BuildGraph(keps.Proposals{
+		{Title: "A", Filename: kep("sig-node/0001-a.md"), OwningSIG: "sig-node",
+			SeeAlso: []string{"/keps/sig-node/0002-b.md", "https://github.com/kubernetes/kubernetes/issues/3"}},
+		{Title: "B", Filename: kep("sig-node/0002-b.md"), OwningSIG: "sig-node",
+			Replaces: []string{"[KEP-3](../sig-network/0003-c.md#summary)"}},
+		{Title: "C", Filename: kep("sig-network/0003-c.md"), OwningSIG: "sig-network",
+			SupersededBy: []string{"KEP-0002"}, SeeAlso: []string{"TBD", "0004-d"}},
+		{Title: "D", Filename: kep("sig-network/0004-d.md"), OwningSIG: "sig-network"},
+	}, root)
+}
+
+func TestBuildGraph(t *testing.T) {
+	g := testGraph()
+	expected := []keps.Edge{
+		{From: "sig-network/0003-c", To: "sig-network/0004-d", Kind: keps.EdgeSeeAlso},
+		{From: "sig-network/0003-c", To: "sig-node/0002-b", Kind: keps.EdgeSupersededBy},
+		{From: "sig-node/0001-a", To: "https://github.com/kubernetes/kubernetes/issues/3", Kind: keps.EdgeSeeAlso},
+		{From: "sig-node/0001-a", To: "sig-node/0002-b", Kind: keps.EdgeSeeAlso},
+		{From: "sig-node/0002-b", To: "sig-network/0003-c", Kind: keps.EdgeReplaces},
+	}
+	if !reflect.DeepEqual(g.Edges, expected) {
+		t.Fatalf("expected %v but got %v", expected, g.Edges)
+	}
+	if len(g.Nodes) != 5 {
+		t.Fatalf("expected 4 KEPs and an unresolved reference but got %d nodes", len(g.Nodes))
+	}
+	if n := g.Node("kep-4"); n == nil || n.Title != "D" {
+		t.Fatalf("expected kep-4 to resolve to D but got %v", n)
+	}
+}
+
+func TestNeighbourhood(t *testing.T) {
+	g := testGraph()
+	testcases := []struct {
+		depth    int
+		expected []string
+	}{
+		{1, []string{"sig-network/0003-c", "sig-node/0001-a", "sig-node/0002-b"}},
+		{0, []string{"https://github.com/kubernetes/kubernetes/issues/3", "sig-network/0003-c", "sig-network/0004-d", "sig-node/0001-a", "sig-node/0002-b"}},
+	}
+	for _, tc := range testcases {
+		near := g.Neighbourhood(g.Node("0002"), tc.depth)
+		sub := g.Subgraph(func(n *keps.Node) bool { return near[n] })
+		var ids []string
+		for _, n := range sub.Nodes {
+			ids = append(ids, n.ID)
+		}
+		if !reflect.DeepEqual(ids, tc.expected) {
+			t.Fatalf("depth %d: expected %v but got %v", tc.depth, tc.expected, ids)
+		}
+		for _, e := range sub.Edges {
+			if !near[g.Node(e.From)] || !near[g.Node(e.To)] {
+				t.Fatalf("depth %d: edge %v leaves the subgraph", tc.depth, e)
+			}
+		}
+	}
+}
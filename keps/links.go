@@ -0,0 +1,288 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+// Link is a markdown link or image in a KEP body.
+type Link struct {
+	Text string
+	URL  string
+	// Line and Column are the 1-based position of the link in the KEP file.
+	Line   int
+	Column int
+}
+
+var (
+	inlineLinkRe    = regexp.MustCompile(`!?\[([^\]]*)\]\(\s*<?([^)\s>]*)>?(?:\s+["'(][^)]*)?\)`)
+	referenceLinkRe = regexp.MustCompile(`^\s{0,3}\[([^\]]+)\]:\s*<?([^\s>]+)>?`)
+	codeSpanRe      = regexp.MustCompile("`[^`]*`")
+	inlineCommentRe = regexp.MustCompile(`<!--.*?-->`)
+	htmlAnchorRe    = regexp.MustCompile(`<a\s+[^>]*(?:name|id)\s*=\s*["']([^"']+)["']`)
+)
+
+// ExtractLinks returns the inline links, images and reference definitions in
+// a markdown body. firstLine is the line number of the first line of body in
+// the file. Links inside code and HTML comments are ignored.
+func ExtractLinks(body string, firstLine int) []Link {
+	var links []Link
+	scanMarkdown(body, func(i int, line string, rendered bool) {
+		if !rendered {
+			return
+		}
+		// blank out code and comments so columns still line up
+		line = codeSpanRe.ReplaceAllStringFunc(line, blank)
+		line = inlineCommentRe.ReplaceAllStringFunc(line, blank)
+		if m := referenceLinkRe.FindStringSubmatchIndex(line); m != nil {
+			links = append(links, Link{
+				Text:   line[m[2]:m[3]],
+				URL:    line[m[4]:m[5]],
+				Line:   firstLine + i,
+				Column: strings.Index(line, "[") + 1,
+			})
+			return
+		}
+		for _, m := range inlineLinkRe.FindAllStringSubmatchIndex(line, -1) {
+			links = append(links, Link{
+				Text:   line[m[2]:m[3]],
+				URL:    line[m[4]:m[5]],
+				Line:   firstLine + i,
+				Column: m[0] + 1,
+			})
+		}
+	})
+	return links
+}
+
+func blank(s string) string {
+	return strings.Repeat(" ", len(s))
+}
+
+// LinkChecker finds links in KEP bodies whose targets do not exist in the
+// local checkout. External links are only checked when External is set.
+type LinkChecker struct {
+	// Root is the directory absolute paths such as /keps/README.md are
+	// resolved against. When empty the enclosing git checkout is used.
+	Root string
+	// External enables checking http and https links over the network.
+	External bool
+	Client   *http.Client
+
+	parser  Parser
+	anchors map[string]map[string]bool
+	// external caches the result of checking each external URL, so a link
+	// that appears in many KEPs is only requested once.
+	external map[string]linkResult
+}
+
+// linkResult is why a link is broken, if it is.
+type linkResult struct {
+	reason string
+	ok     bool
+}
+
+// NewLinkChecker returns a LinkChecker that only checks local links.
+func NewLinkChecker(root string) *LinkChecker {
+	return &LinkChecker{
+		Root:     root,
+		Client:   &http.Client{Timeout: 10 * time.Second},
+		anchors:  map[string]map[string]bool{},
+		external: map[string]linkResult{},
+	}
+}
+
+// Check reports every broken link in the proposal. The returned error is a
+// validations.ErrorList.
+func (c *LinkChecker) Check(p *Proposal) error {
+	body, bodyLine := p.Contents, 1
+	if p.FrontMatter != nil {
+		body, bodyLine = string(p.FrontMatter.Body), p.FrontMatter.BodyLine()
+	}
+	own := anchorSet(p)
+	var errs validations.ErrorList
+	for _, link := range ExtractLinks(body, bodyLine) {
+		err := validations.ValidateLink(link.URL, func(target string) (string, bool) {
+			return c.resolve(p.Filename, own, target)
+		})
+		if err != nil {
+			errs = append(errs, &validations.Error{Line: link.Line, Column: link.Column, Err: err})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (c *LinkChecker) resolve(filename string, own map[string]bool, target string) (string, bool) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return err.Error(), false
+	}
+	switch u.Scheme {
+	case "":
+	case "http", "https":
+		if !c.External {
+			return "", true
+		}
+		return c.resolveExternal(target)
+	default:
+		// mailto: and friends can't be checked
+		return "", true
+	}
+	if u.Host != "" {
+		return "", true
+	}
+
+	if u.Path == "" {
+		if u.Fragment == "" || own[strings.ToLower(u.Fragment)] {
+			return "", true
+		}
+		return fmt.Sprintf("no heading or anchor #%s", u.Fragment), false
+	}
+
+	var path string
+	if strings.HasPrefix(u.Path, "/") {
+		root := c.root(filename)
+		if root == "" {
+			return "", true
+		}
+		path = filepath.Join(root, filepath.FromSlash(u.Path))
+	} else {
+		path = filepath.Join(filepath.Dir(filename), filepath.FromSlash(u.Path))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "no such file or directory", false
+	}
+	if u.Fragment == "" || info.IsDir() || !strings.HasSuffix(path, ".md") {
+		return "", true
+	}
+	anchors, err := c.fileAnchors(path)
+	if err != nil {
+		return err.Error(), false
+	}
+	if !anchors[strings.ToLower(u.Fragment)] {
+		return fmt.Sprintf("no heading or anchor #%s in %s", u.Fragment, u.Path), false
+	}
+	return "", true
+}
+
+func (c *LinkChecker) resolveExternal(target string) (string, bool) {
+	// The fragment is never sent to the server.
+	if i := strings.Index(target, "#"); i >= 0 {
+		target = target[:i]
+	}
+	if c.external == nil {
+		c.external = map[string]linkResult{}
+	}
+	if r, ok := c.external[target]; ok {
+		return r.reason, r.ok
+	}
+	reason, ok := c.request(target)
+	c.external[target] = linkResult{reason, ok}
+	return reason, ok
+}
+
+// request checks an external URL with a HEAD request, falling back to GET
+// for servers that don't allow HEAD.
+func (c *LinkChecker) request(target string) (string, bool) {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Head(target)
+	if err == nil && resp.StatusCode == http.StatusMethodNotAllowed {
+		resp.Body.Close()
+		resp, err = client.Get(target)
+	}
+	if err != nil {
+		return err.Error(), false
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return resp.Status, false
+	}
+	return "", true
+}
+
+// root returns the configured root or the git checkout filename is in.
+func (c *LinkChecker) root(filename string) string {
+	if c.Root != "" {
+		return c.Root
+	}
+	return gitRoot(filename)
+}
+
+// gitRoot returns the nearest parent of filename that contains a .git
+// directory.
+func gitRoot(filename string) string {
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return ""
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func (c *LinkChecker) fileAnchors(path string) (map[string]bool, error) {
+	if c.anchors == nil {
+		c.anchors = map[string]map[string]bool{}
+	}
+	if anchors, ok := c.anchors[path]; ok {
+		return anchors, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	anchors := anchorSet(c.parser.Parse(f))
+	c.anchors[path] = anchors
+	return anchors, nil
+}
+
+// anchorSet returns every heading anchor and HTML anchor in the proposal.
+func anchorSet(p *Proposal) map[string]bool {
+	set := map[string]bool{}
+	for _, anchor := range Anchors(p.Sections) {
+		set[anchor] = true
+	}
+	for _, m := range htmlAnchorRe.FindAllStringSubmatch(p.Contents, -1) {
+		set[strings.ToLower(m[1])] = true
+	}
+	return set
+}
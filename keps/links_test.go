@@ -0,0 +1,136 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+func TestExtractLinks(t *testing.T) {
+	body := "See [the FAQ](../faq.md) and ![diagram](images/a.png \"A diagram\").\n" +
+		"`[not](a-link.md)` <!-- [nor](this.md) -->\n" +
+		"```\n[inside](code.md)\n```\n" +
+		"[ref]: https://example.com\n"
+	links := keps.ExtractLinks(body, 10)
+	expected := []keps.Link{
+		{Text: "the FAQ", URL: "../faq.md", Line: 10, Column: 5},
+		{Text: "diagram", URL: "images/a.png", Line: 10, Column: 30},
+		{Text: "ref", URL: "https://example.com", Line: 15, Column: 1},
+	}
+	if len(links) != len(expected) {
+		t.Fatalf("expected %d links but got %v", len(expected), links)
+	}
+	for i := range expected {
+		if links[i] != expected[i] {
+			t.Fatalf("expected %+v but got %+v", expected[i], links[i])
+		}
+	}
+}
+
+func TestLinkChecker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"keps/sig-foo/other.md":        "# Other\n\n## Design Details\n\n<a name=\"custom\"></a>\n",
+		"keps/sig-foo/images/diag.png": "png",
+		"keps/README.md":               "# KEPs\n",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	kep := `---
+title: test
+---
+## Summary
+
+- [ok](#summary)
+- [bad anchor](#motivation)
+- [ok](other.md#design-details)
+- [ok](./other.md#custom)
+- [bad anchor](other.md#summary)
+- [missing](missing.md)
+- ![ok](images/diag.png)
+- [ok](/keps/README.md)
+- [external](https://example.invalid/skipped)
+`
+	p := &keps.Parser{}
+	proposal := p.Parse(strings.NewReader(kep))
+	proposal.Filename = filepath.Join(dir, "keps", "sig-foo", "kep.md")
+
+	checker := keps.NewLinkChecker(dir)
+	errs, ok := checker.Check(proposal).(validations.ErrorList)
+	if !ok {
+		t.Fatal("expected broken links")
+	}
+	lines := []int{}
+	for _, err := range errs {
+		if _, ok := err.Err.(*validations.BrokenLink); !ok {
+			t.Fatalf("expected a broken link error but got %v", err)
+		}
+		lines = append(lines, err.Line)
+	}
+	if len(lines) != 3 || lines[0] != 7 || lines[1] != 10 || lines[2] != 11 {
+		t.Fatalf("expected broken links on lines 7, 10 and 11 but got %v", errs)
+	}
+}
+
+func TestLinkCheckerCachesExternalLinks(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	kep := "---\ntitle: test\n---\n[a](" + srv.URL + "/ok)\n[b](" + srv.URL + "/ok#section)\n[c](" + srv.URL + "/missing)\n"
+	checker := keps.NewLinkChecker("")
+	checker.External = true
+	var broken int
+	for i := 0; i < 3; i++ {
+		proposal := (&keps.Parser{}).Parse(strings.NewReader(kep))
+		proposal.Filename = "kep.md"
+		errs, _ := checker.Check(proposal).(validations.ErrorList)
+		broken += len(errs)
+	}
+	if broken != 3 {
+		t.Fatalf("expected the missing link to be broken in each KEP but got %d broken links", broken)
+	}
+	if requests != 2 {
+		t.Fatalf("expected each URL to be requested once but got %d requests", requests)
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chuckha/kepview/keps/validations"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Owners is an OWNERS file.
+type Owners struct {
+	Approvers []string `yaml:"approvers"`
+	Reviewers []string `yaml:"reviewers"`
+	Options   struct {
+		NoParentOwners bool `yaml:"no_parent_owners"`
+	} `yaml:"options"`
+}
+
+// ownersAliases is an OWNERS_ALIASES file.
+type ownersAliases struct {
+	Aliases map[string][]string `yaml:"aliases"`
+}
+
+// OwnersChecker checks that the approvers of a KEP can approve changes to
+// the owning SIG's directory according to the OWNERS and OWNERS_ALIASES
+// files in the local checkout.
+type OwnersChecker struct {
+	// Root is the directory holding OWNERS_ALIASES and the top OWNERS file.
+	// When empty the enclosing git checkout is used.
+	Root string
+
+	owners  map[string]*Owners
+	aliases map[string]map[string][]string
+}
+
+// NewOwnersChecker returns an OwnersChecker for the checkout at root.
+func NewOwnersChecker(root string) *OwnersChecker {
+	return &OwnersChecker{
+		Root:    root,
+		owners:  map[string]*Owners{},
+		aliases: map[string]map[string][]string{},
+	}
+}
+
+// Check warns about every approver of the proposal who can't approve changes
+// to the owning SIG's directory. The returned error is a
+// validations.ErrorList.
+func (c *OwnersChecker) Check(p *Proposal) error {
+	root := c.Root
+	if root == "" {
+		root = gitRoot(p.Filename)
+	}
+	if root == "" || len(p.Approvers) == 0 {
+		return nil
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	dir, err := filepath.Abs(filepath.Dir(p.Filename))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	dir = sigDirectory(dir, root, p.OwningSIG)
+	allowed, err := c.Approvers(dir, root)
+	if err != nil {
+		return err
+	}
+	if len(allowed) == 0 {
+		// Without any OWNERS files nobody's rights can be checked.
+		return nil
+	}
+	line, column := 1, 0
+	if p.FrontMatter != nil {
+		line, column = keyPosition(p.FrontMatter.Metadata, p.FrontMatter.MetadataLine())("approvers")
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		rel = dir
+	}
+	errs := validations.ValidateApprovers(p.Approvers, allowed, filepath.ToSlash(rel), line, column)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// sigDirectory returns the directory named after the owning SIG next to dir
+// or one of its parents below root, such as keps/sig-network, or dir itself.
+func sigDirectory(dir, root, sig string) string {
+	sig = strings.TrimSpace(sig)
+	if sig == "" || strings.ContainsAny(sig, `/\`) {
+		return dir
+	}
+	for d := dir; within(d, root); d = filepath.Dir(d) {
+		if filepath.Base(d) == sig {
+			return d
+		}
+		if info, err := os.Stat(filepath.Join(d, sig)); err == nil && info.IsDir() {
+			return filepath.Join(d, sig)
+		}
+		if d == root || d == filepath.Dir(d) {
+			break
+		}
+	}
+	return dir
+}
+
+// Approvers returns the lower cased handles that can approve changes to dir:
+// the approvers in its OWNERS file and those of its parents up to root, with
+// aliases expanded.
+func (c *OwnersChecker) Approvers(dir, root string) (map[string]bool, error) {
+	aliases, err := c.loadAliases(root)
+	if err != nil {
+		return nil, err
+	}
+	allowed := map[string]bool{}
+	for d := dir; ; d = filepath.Dir(d) {
+		owners, err := c.loadOwners(d)
+		if err != nil {
+			return nil, err
+		}
+		if owners != nil {
+			for _, approver := range owners.Approvers {
+				approver = ownersHandle(approver)
+				if members, ok := aliases[approver]; ok {
+					for _, member := range members {
+						allowed[ownersHandle(member)] = true
+					}
+					continue
+				}
+				allowed[approver] = true
+			}
+			if owners.Options.NoParentOwners {
+				break
+			}
+		}
+		if d == root || d == filepath.Dir(d) || !within(d, root) {
+			break
+		}
+	}
+	return allowed, nil
+}
+
+// ownersHandle normalises an entry in an OWNERS or OWNERS_ALIASES file to a
+// lower cased handle without the @.
+func ownersHandle(entry string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(entry)), "@")
+}
+
+// within returns true if dir is root or below it. Comparing paths rather than
+// strings keeps /repo-other from counting as below /repo.
+func within(dir, root string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (c *OwnersChecker) loadOwners(dir string) (*Owners, error) {
+	if c.owners == nil {
+		c.owners = map[string]*Owners{}
+	}
+	if owners, ok := c.owners[dir]; ok {
+		return owners, nil
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "OWNERS"))
+	if os.IsNotExist(err) {
+		c.owners[dir] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	owners := &Owners{}
+	if err := yaml.Unmarshal(data, owners); err != nil {
+		return nil, errors.Wrapf(err, "error reading %v", filepath.Join(dir, "OWNERS"))
+	}
+	c.owners[dir] = owners
+	return owners, nil
+}
+
+func (c *OwnersChecker) loadAliases(root string) (map[string][]string, error) {
+	if c.aliases == nil {
+		c.aliases = map[string]map[string][]string{}
+	}
+	if aliases, ok := c.aliases[root]; ok {
+		return aliases, nil
+	}
+	path := filepath.Join(root, "OWNERS_ALIASES")
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		c.aliases[root] = map[string][]string{}
+		return c.aliases[root], nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f := &ownersAliases{}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, errors.Wrapf(err, "error reading %v", path)
+	}
+	aliases := map[string][]string{}
+	for name, members := range f.Aliases {
+		aliases[ownersHandle(name)] = members
+	}
+	c.aliases[root] = aliases
+	return aliases, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+func TestOwnersChecker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"OWNERS_ALIASES":        "aliases:\n  sig-foo-leads:\n    - Alice\n    - bob\n    - \"@Dave\"\n",
+		"keps/OWNERS":           "approvers:\n  - kep-admin\n",
+		"keps/sig-foo/OWNERS":   "approvers:\n  - sig-foo-leads\n",
+		"keps/sig-bar/OWNERS":   "options:\n  no_parent_owners: true\napprovers:\n  - carol\n",
+		"keps/sig-foo/a/kep.md": "",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testcases := []struct {
+		name      string
+		sig       string
+		approvers string
+		expected  []string
+	}{
+		{
+			name:      "aliases and parent owners",
+			sig:       "sig-foo",
+			approvers: `["@alice", "Bob (@bob)", "kep-admin"]`,
+		},
+		{
+			name:      "alias members written with an @",
+			sig:       "sig-foo",
+			approvers: `["@dave"]`,
+		},
+		{
+			name:      "approver without rights",
+			sig:       "sig-foo",
+			approvers: `["@alice", "@mallory"]`,
+			expected:  []string{"@mallory is not an approver in the OWNERS files for keps/sig-foo"},
+		},
+		{
+			name:      "no parent owners",
+			sig:       "sig-bar",
+			approvers: `["@carol", "@kep-admin"]`,
+			expected:  []string{"@kep-admin is not an approver in the OWNERS files for keps/sig-bar"},
+		},
+	}
+	checker := keps.NewOwnersChecker(dir)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := (&keps.Parser{}).Parse(strings.NewReader("---\ntitle: test\nowning-sig: " + tc.sig + "\napprovers: " + tc.approvers + "\n---\n"))
+			p.Filename = filepath.Join(dir, "keps", "sig-foo", "a", "kep.md")
+			err := checker.Check(p)
+			if len(tc.expected) == 0 {
+				if err != nil {
+					t.Fatalf("expected no errors but got %v", err)
+				}
+				return
+			}
+			errs, ok := err.(validations.ErrorList)
+			if !ok || len(errs) != len(tc.expected) {
+				t.Fatalf("expected %v but got %v", tc.expected, err)
+			}
+			for i, e := range tc.expected {
+				if errs[i].Err.Error() != e || errs[i].Line != 4 || errs[i].Severity != validations.SeverityWarning {
+					t.Fatalf("expected %q on line 4 but got %v", e, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOwnersApproversStayBelowRoot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// repo-other shares a prefix with repo but isn't below it.
+	files := map[string]string{
+		"repo/OWNERS":         "approvers:\n  - insider\n",
+		"repo-other/OWNERS":   "approvers:\n  - outsider\n",
+		"repo-other/keps/kep": "",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root := filepath.Join(dir, "repo")
+	allowed, err := keps.NewOwnersChecker(root).Approvers(filepath.Join(dir, "repo-other", "keps"), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(allowed) != 0 {
+		t.Fatalf("expected no approvers outside the root but got %v", allowed)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps
+
+import "github.com/chuckha/kepview/keps/validations"
+
+// Roles people have on a KEP.
+const (
+	RoleAuthor   = "author"
+	RoleReviewer = "reviewer"
+	RoleApprover = "approver"
+)
+
+// Person is someone listed on a KEP.
+type Person struct {
+	// Handle is the lower cased GitHub handle without the @.
+	Handle string `json:"handle"`
+	// Name is the display name, if the KEP gave one.
+	Name string `json:"name,omitempty"`
+	Role string `json:"role"`
+}
+
+// People returns the authors, reviewers and approvers of the KEP, in that
+// order. Entries that aren't GitHub handles are skipped.
+func (p *Proposal) People() []Person {
+	var people []Person
+	for _, list := range []struct {
+		role    string
+		entries []string
+	}{
+		{RoleAuthor, p.Authors},
+		{RoleReviewer, p.Reviewers},
+		{RoleApprover, p.Approvers},
+	} {
+		for _, entry := range list.entries {
+			handle, name, ok := validations.ParsePerson(entry)
+			if !ok {
+				continue
+			}
+			people = append(people, Person{Handle: handle, Name: name, Role: list.role})
+		}
+	}
+	return people
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chuckha/kepview/keps"
+)
+
+func TestPeople(t *testing.T) {
+	p := &keps.Proposal{
+		Authors:   []string{"@Jane", "Bob Smith (@bob)"},
+		Reviewers: []string{"TBD", "not a handle!"},
+		Approvers: []string{"jane"},
+	}
+	expected := []keps.Person{
+		{Handle: "jane", Role: keps.RoleAuthor},
+		{Handle: "bob", Name: "Bob Smith", Role: keps.RoleAuthor},
+		{Handle: "jane", Role: keps.RoleApprover},
+	}
+	if people := p.People(); !reflect.DeepEqual(people, expected) {
+		t.Fatalf("expected %v but got %v", expected, people)
+	}
+}
@@ -17,10 +17,10 @@ limitations under the License.
 package keps
 
 import (
-	"bufio"
-	"bytes"
 	"io"
+	"io/ioutil"
 	"strings"
+	"time"
 
 	"github.com/chuckha/kepview/keps/validations"
 	"github.com/pkg/errors"
@@ -49,50 +49,148 @@ type Proposal struct {
 	SupersededBy      []string `yaml:"superseded-by,omitempty"`
 
 	Filename string `yaml:"-"`
+	// Error holds every problem found in the KEP. It is a
+	// validations.ErrorList when the problems have positions and severities;
+	// use HasErrors to tell whether any of them fail the KEP.
 	Error    error  `yaml:"-"`
 	Contents string `yaml:"-"`
+
+	FrontMatter  *FrontMatter               `yaml:"-" json:"-"`
+	Sections     []*Section                 `yaml:"-" json:"-"`
+	Suppressions []*validations.Suppression `yaml:"-" json:"-"`
 }
 
-type Parser struct{}
+// HasErrors returns true if the KEP has problems more serious than warnings.
+func (p *Proposal) HasErrors() bool {
+	if p.Error == nil {
+		return false
+	}
+	errs, ok := p.Error.(validations.ErrorList)
+	return !ok || errs.HasAtLeast(validations.SeverityError)
+}
 
-func (p *Parser) Parse(in io.Reader) *Proposal {
-	scanner := bufio.NewScanner(in)
-	count := 0
-	metadata := []byte{}
-	var body bytes.Buffer
-	for scanner.Scan() {
-		line := scanner.Text() + "\n"
-		body.WriteString(line)
-		if count == 2 {
-			continue
-		}
-		if strings.Contains(line, "---") {
-			count++
+// Check validates the body of a parsed KEP.
+type Check func(*Proposal) error
+
+// Validate runs checks on a KEP whose metadata has no errors and returns every
+// result, including the metadata warnings, with the severities in config. The
+// default rules are used when config is nil.
+// Problems the KEP suppresses are dropped and unused suppressions reported. A
+// check that fails with anything other than a validations.ErrorList stops
+// the validation.
+func (p *Proposal) Validate(checks []Check, config *validations.Config) error {
+	if p.HasErrors() {
+		return p.Error
+	}
+	if config == nil {
+		config = validations.DefaultConfig()
+	}
+	found, _ := p.Error.(validations.ErrorList)
+	// Copy the metadata results so the checks' results don't end up in p.Error.
+	errs := append(validations.ErrorList(nil), found...)
+	for _, check := range checks {
+		err := check(p)
+		if err == nil {
 			continue
 		}
-		if count == 1 {
-			metadata = append(metadata, []byte(line)...)
+		list, ok := err.(validations.ErrorList)
+		if !ok {
+			return err
 		}
+		errs = append(errs, list...)
+	}
+	errs = validations.Suppress(config.Apply(errs), p.Suppressions)
+	errs = append(errs, config.Apply(validations.UnusedSuppressions(p.Suppressions))...)
+	if len(errs) == 0 {
+		return nil
 	}
+	return errs
+}
+
+// Parser parses KEP files and validates their metadata.
+type Parser struct {
+	// Config holds the metadata rules. The default rules are used when nil.
+	Config *validations.Config
+	// Now returns the date metadata such as last-updated is checked against.
+	// time.Now is used when nil.
+	Now func() time.Time
+}
+
+func (p *Parser) Parse(in io.Reader) *Proposal {
+	content, err := ioutil.ReadAll(in)
 	proposal := &Proposal{
-		Contents: body.String(),
+		Contents: string(content),
 	}
-	if err := scanner.Err(); err != nil {
+	if err != nil {
 		proposal.Error = errors.Wrap(err, "error reading file")
 		return proposal
 	}
+	frontMatter, err := SplitFrontMatter(content)
+	proposal.FrontMatter = frontMatter
+	proposal.Sections = ParseSections(string(frontMatter.Body), frontMatter.BodyLine())
+	// A file without any front matter has empty metadata.
+	if err != nil && err != ErrNoFrontMatter {
+		proposal.Error = errors.Wrap(err, "error finding KEP metadata")
+		return proposal
+	}
+	metadata := frontMatter.Metadata
+	metadataLine := frontMatter.MetadataLine()
+
+	config := p.Config
+	if config == nil {
+		config = validations.DefaultConfig()
+	}
+
+	suppressions, errs := validations.ParseSuppressions(metadata, metadataLine)
+	proposal.Suppressions = suppressions
+	errs = config.Apply(errs)
+	// check collects errors that aren't suppressed by the KEP.
+	check := func(found validations.ErrorList) {
+		errs = append(errs, validations.Suppress(config.Apply(found), suppressions)...)
+	}
 
 	// First do structural checks
 	test := map[interface{}]interface{}{}
 	if err := yaml.Unmarshal(metadata, test); err != nil {
-		proposal.Error = errors.Wrap(err, "error unmarshaling YAML")
+		check(validations.YAMLErrors(err, metadataLine))
+		if len(errs) > 0 {
+			proposal.Error = errs
+		}
 		return proposal
 	}
-	if err := validations.ValidateStructure(test); err != nil {
-		proposal.Error = errors.Wrap(err, "error validating KEP metadata")
-		return proposal
+	position := keyPosition(metadata, metadataLine)
+	check(config.ValidateKeys(test, position))
+	check(validations.ValidatePeople(test, position))
+	failed := errs.HasAtLeast(validations.SeverityError)
+	if !failed {
+		now := time.Now
+		if p.Now != nil {
+			now = p.Now
+		}
+		check(validations.ValidateWarnings(test, position, now()))
 	}
 
-	proposal.Error = yaml.Unmarshal(metadata, proposal)
+	// The metadata is read even when it has errors so the KEP can still be
+	// listed. Values of the wrong type were already reported above.
+	if err := yaml.Unmarshal(metadata, proposal); err != nil && !failed {
+		check(validations.YAMLErrors(err, metadataLine))
+	}
+	if len(errs) > 0 {
+		proposal.Error = errs
+	}
 	return proposal
 }
+
+// keyPosition finds top level keys in the metadata. Keys that can't be found
+// are reported on the first line of the metadata.
+func keyPosition(metadata []byte, firstLine int) validations.KeyPosition {
+	lines := strings.Split(string(metadata), "\n")
+	return func(key string) (int, int) {
+		for i, line := range lines {
+			if strings.HasPrefix(line, key+":") || strings.HasPrefix(line, `"`+key+`":`) {
+				return firstLine + i, 1
+			}
+		}
+		return firstLine, 0
+	}
+}
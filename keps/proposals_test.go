@@ -17,10 +17,14 @@ limitations under the License.
 package keps_test
 
 import (
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+	"github.com/pkg/errors"
 )
 
 func TestValidParsing(t *testing.T) {
@@ -54,6 +58,28 @@ last-updated: 2018-04-24
 status: provisional
 ---`,
 		},
+		{
+			"body with horizontal rules and tables",
+			`---
+title: test
+authors:
+  - "@jpbetz"
+owning-sig: sig-api-machinery
+reviewers:
+  - "@deads2k"
+approvers:
+  - "@lavalamp"
+creation-date: 2018-04-15
+last-updated: 2018-04-24
+status: provisional
+---
+
+| a | b |
+|---|---|
+
+---
+`,
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -69,3 +95,122 @@ status: provisional
 		})
 	}
 }
+
+func TestParseStaleLastUpdated(t *testing.T) {
+	kep := "---\ntitle: test\neditor: \"@editor\"\nstatus: provisional\nlast-updated: 2019-01-01\n---\n"
+	testcases := []struct {
+		name  string
+		now   time.Time
+		stale bool
+	}{
+		{"recently updated", time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC), false},
+		{"updated over a year ago", time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC), true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &keps.Parser{Now: func() time.Time { return tc.now }}
+			out := p.Parse(strings.NewReader(kep))
+			errs, _ := out.Error.(validations.ErrorList)
+			stale := false
+			for _, err := range errs {
+				stale = stale || err.Code() == "stale-last-updated"
+			}
+			if stale != tc.stale {
+				t.Fatalf("expected stale to be %v but got %v", tc.stale, errs)
+			}
+		})
+	}
+}
+
+func TestParseInvalidPeople(t *testing.T) {
+	kep := "---\ntitle: test\neditor: \"@editor\"\nauthors:\n  - Jane Doe\n  - \"@kubernetes/sig-node-leads\"\n---\n"
+	testcases := []struct {
+		name      string
+		rules     map[string]string
+		hasErrors bool
+	}{
+		{"warnings by default", nil, false},
+		{"configured as errors", map[string]string{"invalid-handle": "error"}, true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := validations.DefaultConfig()
+			for code, severity := range tc.rules {
+				config.Rules[code] = severity
+			}
+			out := (&keps.Parser{Config: config}).Parse(strings.NewReader(kep))
+			if out.HasErrors() != tc.hasErrors {
+				t.Fatalf("expected HasErrors to be %v but got %v", tc.hasErrors, out.Error)
+			}
+			errs, _ := out.Error.(validations.ErrorList)
+			if len(errs) != 1 || errs[0].Code() != "invalid-handle" {
+				t.Fatalf("expected only an invalid-handle problem but got %v", out.Error)
+			}
+			// The metadata is read either way.
+			if out.Title != "test" || len(out.Authors) != 2 {
+				t.Fatalf("expected the metadata to be read but got %q and %v", out.Title, out.Authors)
+			}
+		})
+	}
+}
+
+func TestValidateSuppressions(t *testing.T) {
+	kep := (&keps.Parser{}).Parse(strings.NewReader(`---
+title: test
+authors: [a]
+owning-sig: sig-foo
+reviewers: [b]
+approvers: [c]
+creation-date: 2019-01-01
+last-updated: 2019-01-01
+status: implemented
+# kepval:ignore=missing-editor reason="predates editors"
+# kepval:ignore=stale-toc
+---
+`))
+	err := kep.Validate(nil, validations.DefaultConfig())
+	errs, ok := err.(validations.ErrorList)
+	if !ok {
+		t.Fatalf("expected a list of warnings but got %v", err)
+	}
+	codes := []string{}
+	for _, e := range errs {
+		codes = append(codes, e.Code())
+	}
+	expected := []string{"suppression-without-reason", "unused-suppression"}
+	if strings.Join(codes, " ") != strings.Join(expected, " ") {
+		t.Fatalf("expected %v but got %v", expected, codes)
+	}
+}
+
+func TestValidateChecks(t *testing.T) {
+	kep := (&keps.Parser{}).Parse(strings.NewReader("---\ntitle: test\nstatus: implemented\n---\n"))
+	metadata := kep.Error
+	config := validations.DefaultConfig()
+	config.Rules["missing-section"] = "warning"
+
+	sections := func(p *keps.Proposal) error { return p.ValidateSections(config) }
+	err := kep.Validate([]keps.Check{sections}, config)
+	errs, ok := err.(validations.ErrorList)
+	if !ok || len(errs) <= len(metadata.(validations.ErrorList)) {
+		t.Fatalf("expected the metadata warnings and missing sections but got %v", err)
+	}
+	for _, e := range errs {
+		if e.Code() == "missing-section" && e.Severity != validations.SeverityWarning {
+			t.Fatalf("expected missing sections to be warnings but got %v", e)
+		}
+	}
+	if !reflect.DeepEqual(kep.Error, metadata) {
+		t.Fatalf("expected the KEP's own errors to be left alone but got %v", kep.Error)
+	}
+
+	// Without a config the default rules apply.
+	if err := kep.Validate([]keps.Check{sections}, nil); err == nil {
+		t.Fatal("expected missing sections with the default rules")
+	}
+
+	failure := errors.New("failed")
+	if err := kep.Validate([]keps.Check{func(*keps.Proposal) error { return failure }}, config); err != failure {
+		t.Fatalf("expected the check's error but got %v", err)
+	}
+}
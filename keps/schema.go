@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+// descriptions explain the metadata keys in generated schemas.
+var descriptions = map[string]string{
+	"title":              "The title of the KEP.",
+	"authors":            "The GitHub handles of the people writing the KEP.",
+	"owning-sig":         "The SIG that owns the KEP.",
+	"participating-sigs": "Other SIGs involved in the KEP.",
+	"reviewers":          "The GitHub handles of the people reviewing the KEP.",
+	"approvers":          "The GitHub handles of the people who approve the KEP.",
+	"editor":             "The person managing the KEP process for the authors.",
+	"creation-date":      "The date the KEP was first proposed, as YYYY-MM-DD.",
+	"last-updated":       "The date the KEP last changed, as YYYY-MM-DD.",
+	"status":             "Where the KEP is in its lifecycle, such as provisional, implementable or implemented.",
+	"see-also":           "Related KEPs.",
+	"replaces":           "KEPs this KEP replaces.",
+	"superseded-by":      "KEPs that replace this KEP.",
+}
+
+// Schema returns a JSON Schema for KEP metadata built from the fields of
+// Proposal and the rules in config.
+func Schema(config *validations.Config) *validations.Schema {
+	if config == nil {
+		config = validations.DefaultConfig()
+	}
+	properties := map[string]*validations.Schema{}
+	t := reflect.TypeOf(Proposal{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		property := &validations.Schema{Description: descriptions[key]}
+		switch field.Type.Kind() {
+		case reflect.String:
+			property.Type = validations.SchemaTypes{"string"}
+		case reflect.Slice:
+			property.Type = validations.SchemaTypes{"array"}
+			property.Items = &validations.Schema{Type: validations.SchemaTypes{"string"}}
+		}
+		properties[key] = property
+	}
+	s := config.Schema(properties)
+	s.Title = "KEP metadata"
+	s.Description = "The YAML front matter of a Kubernetes Enhancement Proposal."
+	return s
+}
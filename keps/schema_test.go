@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+func TestSchema(t *testing.T) {
+	config := validations.DefaultConfig()
+	config.Keys["status"].Required = true
+	config.Keys["status"].Enum = []string{"provisional", "implemented"}
+	config.Keys["tracking-issue"] = &validations.KeyRule{Type: validations.TypeDate, AllowEmpty: true}
+
+	data, err := json.Marshal(keps.Schema(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema["$schema"] != validations.SchemaDialect {
+		t.Fatalf("unexpected dialect %v", schema["$schema"])
+	}
+	if !reflect.DeepEqual(schema["required"], []interface{}{"status"}) {
+		t.Fatalf("expected status to be required but got %v", schema["required"])
+	}
+	properties := schema["properties"].(map[string]interface{})
+	testcases := []struct {
+		key      string
+		expected map[string]interface{}
+	}{
+		{"title", map[string]interface{}{
+			"description": "The title of the KEP.",
+			"type":        "string",
+			"minLength":   1.0,
+		}},
+		{"status", map[string]interface{}{
+			"description": properties["status"].(map[string]interface{})["description"],
+			"type":        "string",
+			"minLength":   1.0,
+			"enum":        []interface{}{"provisional", "implemented"},
+		}},
+		{"see-also", map[string]interface{}{
+			"description": "Related KEPs.",
+			"type":        []interface{}{"array", "null"},
+			"items":       map[string]interface{}{"type": "string"},
+		}},
+		{"tracking-issue", map[string]interface{}{
+			"type":   []interface{}{"string", "null"},
+			"format": "date",
+		}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.key, func(t *testing.T) {
+			if !reflect.DeepEqual(properties[tc.key], tc.expected) {
+				t.Fatalf("expected %v but got %v", tc.expected, properties[tc.key])
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps
+
+import (
+	"strings"
+
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+// Section is a markdown heading, the text directly below it and the
+// sections nested under it.
+type Section struct {
+	Title string
+	// Level is the number of #s in the heading.
+	Level int
+	// Line is the 1-based line number of the heading in the KEP file.
+	Line int
+	// Content is the text between the heading and the next heading.
+	Content  string
+	Children []*Section
+}
+
+// Text returns the content of the section including all nested sections.
+func (s *Section) Text() string {
+	var b strings.Builder
+	b.WriteString(s.Content)
+	for _, child := range s.Children {
+		b.WriteString(strings.Repeat("#", child.Level))
+		b.WriteString(" ")
+		b.WriteString(child.Title)
+		b.WriteString("\n")
+		b.WriteString(child.Text())
+	}
+	return b.String()
+}
+
+// Section returns the first section nested under s with the given title.
+// Titles are compared case-insensitively.
+func (s *Section) Section(title string) *Section {
+	return findSection(s.Children, title)
+}
+
+// Section returns the first section in the KEP body with the given title.
+// Titles are compared case-insensitively.
+func (p *Proposal) Section(title string) *Section {
+	return findSection(p.Sections, title)
+}
+
+// ValidateSections checks that the KEP body has every section config requires
+// for its status and that none of them are left as template placeholders. The
+// default sections are required when config is nil. The returned error is a
+// validations.ErrorList.
+func (p *Proposal) ValidateSections(config *validations.Config) error {
+	bodyLine := 1
+	if p.FrontMatter != nil {
+		bodyLine = p.FrontMatter.BodyLine()
+	}
+	errs := config.ValidateSections(p.Status, bodyLine, func(title string) (int, string, bool) {
+		s := p.Section(title)
+		if s == nil {
+			return 0, "", false
+		}
+		return s.Line, s.Text(), true
+	})
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func findSection(sections []*Section, title string) *Section {
+	for _, s := range sections {
+		if strings.EqualFold(s.Title, title) {
+			return s
+		}
+		if found := findSection(s.Children, title); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// Walk calls fn for every section in the tree, parents before children.
+func Walk(sections []*Section, fn func(*Section)) {
+	for _, s := range sections {
+		fn(s)
+		Walk(s.Children, fn)
+	}
+}
+
+// ParseSections builds a section tree from the ATX headings in a markdown
+// body. firstLine is the line number of the first line of body in the file.
+// Headings inside fenced code blocks and HTML comments are ignored.
+func ParseSections(body string, firstLine int) []*Section {
+	var roots []*Section
+	var stack []*Section
+	var current *Section
+	var content strings.Builder
+
+	scanMarkdown(body, func(i int, line string, rendered bool) {
+		level, title, ok := 0, "", false
+		if rendered {
+			level, title, ok = parseHeading(line)
+		}
+		if !ok {
+			content.WriteString(line)
+			return
+		}
+
+		if current != nil {
+			current.Content = content.String()
+		}
+		content.Reset()
+		current = &Section{
+			Title: title,
+			Level: level,
+			Line:  firstLine + i,
+		}
+		for len(stack) > 0 && stack[len(stack)-1].Level >= level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, current)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, current)
+		}
+		stack = append(stack, current)
+	})
+	if current != nil {
+		current.Content = content.String()
+	}
+	return roots
+}
+
+// scanMarkdown calls fn with the 0-based index of every line in body, the
+// line including its line ending, and whether the line is rendered as
+// markdown. Lines inside fenced code blocks and multi-line HTML comments,
+// along with the lines that open them, are not rendered.
+func scanMarkdown(body string, fn func(i int, line string, rendered bool)) {
+	inFence, inComment := false, false
+	fence := ""
+	for i, line := range strings.SplitAfter(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		rendered := !inFence && !inComment
+		switch {
+		case inFence:
+			if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+			}
+		case inComment:
+			if strings.Contains(trimmed, "-->") {
+				inComment = false
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			inFence, rendered = true, false
+			fence = trimmed[:3]
+		case strings.HasPrefix(trimmed, "<!--") && !strings.Contains(trimmed, "-->"):
+			inComment, rendered = true, false
+		}
+		fn(i, line, rendered)
+	}
+}
+
+// parseHeading recognises ATX headings such as "## Summary" and "## Summary ##".
+func parseHeading(line string) (int, string, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	if indent > 3 {
+		return 0, "", false
+	}
+	line = line[indent:]
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0, "", false
+	}
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, "", false
+	}
+	title := strings.TrimSpace(rest)
+	// drop an optional closing sequence of #s
+	if stripped := strings.TrimRight(title, "#"); stripped != title {
+		if stripped == "" || strings.HasSuffix(stripped, " ") || strings.HasSuffix(stripped, "\t") {
+			title = strings.TrimSpace(stripped)
+		}
+	}
+	return level, title, true
+}
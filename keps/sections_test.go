@@ -0,0 +1,154 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+const sectionedKEP = `---
+title: test
+---
+# KEP-1: Test
+
+## Summary
+
+A short summary.
+
+## Motivation
+
+### Goals
+
+- be tested
+
+### Non-Goals ###
+
+` + "```" + `
+# not a heading
+` + "```" + `
+
+<!--
+## Not a heading either
+-->
+
+## Design Details
+
+#not-a-heading
+`
+
+func TestParseSections(t *testing.T) {
+	p := &keps.Parser{}
+	proposal := p.Parse(strings.NewReader(sectionedKEP))
+
+	if len(proposal.Sections) != 1 {
+		t.Fatalf("expected a single top level section but got %d", len(proposal.Sections))
+	}
+	root := proposal.Sections[0]
+	if root.Title != "KEP-1: Test" || root.Line != 4 {
+		t.Fatalf("unexpected root section %q on line %d", root.Title, root.Line)
+	}
+	if len(root.Children) != 3 {
+		t.Fatalf("expected 3 children but got %d", len(root.Children))
+	}
+
+	testcases := []struct {
+		title   string
+		level   int
+		line    int
+		content string
+	}{
+		{"Summary", 2, 6, "\nA short summary.\n\n"},
+		{"goals", 3, 12, "\n- be tested\n\n"},
+		{"Non-Goals", 3, 16, "\n```\n# not a heading\n```\n\n<!--\n## Not a heading either\n-->\n\n"},
+		{"Design Details", 2, 26, "\n#not-a-heading\n"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.title, func(t *testing.T) {
+			s := proposal.Section(tc.title)
+			if s == nil {
+				t.Fatalf("expected to find section %q", tc.title)
+			}
+			if s.Level != tc.level {
+				t.Fatalf("expected level %d but got %d", tc.level, s.Level)
+			}
+			if s.Line != tc.line {
+				t.Fatalf("expected line %d but got %d", tc.line, s.Line)
+			}
+			if s.Content != tc.content {
+				t.Fatalf("expected content %q but got %q", tc.content, s.Content)
+			}
+		})
+	}
+
+	motivation := proposal.Section("Motivation")
+	if motivation.Section("Goals") == nil {
+		t.Fatal("expected Goals to be nested under Motivation")
+	}
+	if !strings.Contains(motivation.Text(), "### Goals\n\n- be tested") {
+		t.Fatalf("expected Motivation text to include its children but got %q", motivation.Text())
+	}
+	if proposal.Section("Graduation Criteria") != nil {
+		t.Fatal("did not expect to find a missing section")
+	}
+}
+
+func TestProposalValidateSections(t *testing.T) {
+	p := &keps.Parser{}
+	proposal := p.Parse(strings.NewReader(`---
+title: test
+status: implementable
+---
+# KEP-1: Test
+
+## Summary
+
+A short summary.
+
+## Motivation
+
+Reasons.
+
+## Proposal
+
+A proposal.
+
+## Design Details
+
+### Test Plan
+
+<!-- describe the tests -->
+
+### Graduation Criteria
+
+Beta after a release.
+`))
+	err := proposal.ValidateSections(nil)
+	errs, ok := err.(validations.ErrorList)
+	if !ok {
+		t.Fatalf("expected an ErrorList but got %v", err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("expected a single error but got %v", errs)
+	}
+	if errs[0].Line != 21 {
+		t.Fatalf("expected the Test Plan placeholder on line 21 but got line %d", errs[0].Line)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+const (
+	tocStart = "<!-- toc -->"
+	tocEnd   = "<!-- /toc -->"
+)
+
+// Slug returns the anchor GitHub generates for a heading: lower case, with
+// punctuation removed and spaces replaced by hyphens.
+func Slug(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(title)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsNumber(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// Anchors returns the anchor of every section. Repeated headings get a
+// numeric suffix the same way GitHub does, so the second "Example" heading is
+// "example-1".
+func Anchors(sections []*Section) map[*Section]string {
+	anchors := map[*Section]string{}
+	seen := map[string]int{}
+	Walk(sections, func(s *Section) {
+		slug := Slug(s.Title)
+		anchor := slug
+		if n, ok := seen[slug]; ok {
+			anchor = fmt.Sprintf("%s-%d", slug, n)
+		}
+		seen[slug]++
+		anchors[s] = anchor
+	})
+	return anchors
+}
+
+// TableOfContents renders a nested markdown list linking to every heading
+// below the KEP title. A "Table of Contents" heading is left out.
+func TableOfContents(sections []*Section) string {
+	anchors := Anchors(sections)
+	var b strings.Builder
+	Walk(sections, func(s *Section) {
+		if s.Level < 2 || strings.EqualFold(s.Title, "Table of Contents") {
+			return
+		}
+		fmt.Fprintf(&b, "%s- [%s](#%s)\n", strings.Repeat("  ", s.Level-2), s.Title, anchors[s])
+	})
+	return b.String()
+}
+
+// findTableOfContents returns the byte offsets of the text between the toc
+// markers in body.
+func findTableOfContents(body string) (int, int, bool) {
+	start, end := -1, -1
+	offset := 0
+	for _, line := range strings.SplitAfter(body, "\n") {
+		switch strings.TrimSpace(line) {
+		case tocStart:
+			if start < 0 {
+				start = offset + len(line)
+			}
+		case tocEnd:
+			if start >= 0 {
+				end = offset
+				return start, end, true
+			}
+		}
+		offset += len(line)
+	}
+	return 0, 0, false
+}
+
+// UpdateTableOfContents regenerates the text between the toc markers in body
+// from the given sections. It returns false if body has no toc markers.
+func UpdateTableOfContents(body string, sections []*Section) (string, bool) {
+	start, end, ok := findTableOfContents(body)
+	if !ok {
+		return body, false
+	}
+	return body[:start] + TableOfContents(sections) + body[end:], true
+}
+
+// ValidateTableOfContents checks the text between the toc markers matches the
+// headings of the KEP. KEPs without toc markers pass. The returned error is a
+// validations.ErrorList.
+func (p *Proposal) ValidateTableOfContents() error {
+	body, bodyLine := p.Contents, 1
+	if p.FrontMatter != nil {
+		body, bodyLine = string(p.FrontMatter.Body), p.FrontMatter.BodyLine()
+	}
+	start, end, ok := findTableOfContents(body)
+	if !ok {
+		return nil
+	}
+	firstLine := bodyLine + strings.Count(body[:start], "\n")
+	if err := validations.ValidateTableOfContents(firstLine, body[start:end], TableOfContents(p.Sections)); err != nil {
+		return validations.ErrorList{err}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keps_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chuckha/kepview/keps"
+	"github.com/chuckha/kepview/keps/validations"
+)
+
+func TestSlug(t *testing.T) {
+	testcases := []struct {
+		title string
+		slug  string
+	}{
+		{"Summary", "summary"},
+		{"Non-Goals", "non-goals"},
+		{"KEP-1234: A `kubectl` Feature!", "kep-1234-a-kubectl-feature"},
+		{"Risks and Mitigations", "risks-and-mitigations"},
+		{"Upgrade / Downgrade Strategy", "upgrade--downgrade-strategy"},
+		{"snake_case", "snake_case"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.title, func(t *testing.T) {
+			if slug := keps.Slug(tc.title); slug != tc.slug {
+				t.Fatalf("expected %q but got %q", tc.slug, slug)
+			}
+		})
+	}
+}
+
+const tocKEP = `---
+title: test
+---
+# KEP-1: Test
+
+<!-- toc -->
+- [Summary](#summary)
+<!-- /toc -->
+
+## Summary
+
+## Motivation
+
+### Example
+
+## Proposal
+
+### Example
+`
+
+const expectedTOC = `- [Summary](#summary)
+- [Motivation](#motivation)
+  - [Example](#example)
+- [Proposal](#proposal)
+  - [Example](#example-1)
+`
+
+func TestTableOfContents(t *testing.T) {
+	p := &keps.Parser{}
+	proposal := p.Parse(strings.NewReader(tocKEP))
+
+	if toc := keps.TableOfContents(proposal.Sections); toc != expectedTOC {
+		t.Fatalf("expected %q but got %q", expectedTOC, toc)
+	}
+
+	errs, ok := proposal.ValidateTableOfContents().(validations.ErrorList)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("expected a stale table of contents error but got %v", errs)
+	}
+	if errs[0].Line != 8 {
+		t.Fatalf("expected the error on line 8 but got %d", errs[0].Line)
+	}
+
+	updated, ok := keps.UpdateTableOfContents(string(proposal.FrontMatter.Body), proposal.Sections)
+	if !ok {
+		t.Fatal("expected to find the toc markers")
+	}
+	if !strings.Contains(updated, "<!-- toc -->\n"+expectedTOC+"<!-- /toc -->\n") {
+		t.Fatalf("expected the toc to be replaced but got %q", updated)
+	}
+
+	fixed := p.Parse(strings.NewReader("---\ntitle: test\n---\n" + updated))
+	if err := fixed.ValidateTableOfContents(); err != nil {
+		t.Fatalf("expected the regenerated toc to be valid: %v", err)
+	}
+}
+
+func TestTableOfContentsWithoutMarkers(t *testing.T) {
+	p := &keps.Parser{}
+	proposal := p.Parse(strings.NewReader("---\ntitle: test\n---\n## Summary\n"))
+	if err := proposal.ValidateTableOfContents(); err != nil {
+		t.Fatalf("did not expect an error without toc markers: %v", err)
+	}
+	if _, ok := keps.UpdateTableOfContents(string(proposal.FrontMatter.Body), proposal.Sections); ok {
+		t.Fatal("did not expect to update a body without toc markers")
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type InvalidStatusTransition struct {
+	from string
+	to   string
+}
+
+func (i *InvalidStatusTransition) Error() string {
+	return fmt.Sprintf("status cannot change from %q to %q", i.from, i.to)
+}
+
+type LastUpdatedNotBumped struct {
+	before string
+	after  string
+}
+
+func (l *LastUpdatedNotBumped) Error() string {
+	return fmt.Sprintf("\"last-updated\" went from %q back to %q", l.before, l.after)
+}
+
+// statusTransitions lists the statuses each status may move to.
+var statusTransitions = map[string][]string{
+	"provisional":   {"implementable", "deferred", "rejected", "withdrawn", "replaced"},
+	"implementable": {"provisional", "implemented", "deferred", "withdrawn", "replaced"},
+	"implemented":   {"withdrawn", "replaced"},
+	"deferred":      {"provisional", "implementable", "rejected", "withdrawn", "replaced"},
+	"rejected":      {"provisional"},
+	"withdrawn":     {"provisional"},
+	"replaced":      {},
+}
+
+// ValidateStatusTransition returns an error if a KEP may not move from one
+// status to the other. Unknown statuses are left to other rules.
+func ValidateStatusTransition(from, to string) error {
+	from, to = strings.ToLower(from), strings.ToLower(to)
+	if from == to {
+		return nil
+	}
+	allowed, ok := statusTransitions[from]
+	if !ok {
+		return nil
+	}
+	if _, ok := statusTransitions[to]; !ok {
+		return nil
+	}
+	for _, status := range allowed {
+		if status == to {
+			return nil
+		}
+	}
+	return &InvalidStatusTransition{from, to}
+}
+
+// ValidateLastUpdated returns an error if a KEP changed but its last-updated
+// date went backwards. A change made the same day may keep the date. Dates
+// that don't parse are left to other rules.
+func ValidateLastUpdated(before, after string) error {
+	b, err := time.Parse("2006-01-02", before)
+	if err != nil {
+		return nil
+	}
+	a, err := time.Parse("2006-01-02", after)
+	if err != nil {
+		return nil
+	}
+	if a.Before(b) {
+		return &LastUpdatedNotBumped{before, after}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"testing"
+)
+
+func TestValidateStatusTransition(t *testing.T) {
+	testcases := []struct {
+		from  string
+		to    string
+		valid bool
+	}{
+		{"provisional", "provisional", true},
+		{"provisional", "implementable", true},
+		{"implementable", "implemented", true},
+		{"Provisional", "Implemented", false},
+		{"implemented", "provisional", false},
+		{"replaced", "implementable", false},
+		{"some status", "implemented", true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.from+" to "+tc.to, func(t *testing.T) {
+			err := ValidateStatusTransition(tc.from, tc.to)
+			if tc.valid && err != nil {
+				t.Fatalf("did not expect an error: %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestValidateLastUpdated(t *testing.T) {
+	testcases := []struct {
+		before string
+		after  string
+		valid  bool
+	}{
+		{"2019-01-01", "2019-02-01", true},
+		// A change made the same day as the base version keeps the date.
+		{"2019-01-01", "2019-01-01", true},
+		{"2019-02-01", "2019-01-01", false},
+		{"sometime", "2019-01-01", true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.before+" to "+tc.after, func(t *testing.T) {
+			err := ValidateLastUpdated(tc.before, tc.after)
+			if tc.valid && err != nil {
+				t.Fatalf("did not expect an error: %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,453 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigFilename is the name of the project configuration file.
+const ConfigFilename = ".kepval.yaml"
+
+// Key types understood by KeyRule.
+const (
+	TypeString = "string"
+	TypeList   = "list"
+	TypeDate   = "date"
+)
+
+// SeverityOff disables a rule in Config.Rules.
+const SeverityOff = "off"
+
+// Config declares the metadata a project's KEPs have and how strictly each
+// rule is enforced. Projects that adopted the KEP process with different
+// fields describe them in a .kepval.yaml file.
+type Config struct {
+	// Keys maps each metadata key to the rule it must follow. Keys that
+	// aren't listed are not checked.
+	Keys map[string]*KeyRule `yaml:"keys"`
+	// Rules maps rule codes, such as missing-section, to a severity: error,
+	// warning, info or off.
+	Rules map[string]string `yaml:"rules"`
+	// SchemaFile is a JSON Schema the metadata must also match, relative to
+	// the configuration file.
+	SchemaFile string `yaml:"schema"`
+	// SIGs lists the groups that may own or participate in a KEP.
+	SIGs []string `yaml:"sigs"`
+	// SIGsFile is a kubernetes/community sigs.yaml naming the groups that may
+	// own or participate in a KEP, relative to the configuration file.
+	SIGsFile string `yaml:"sigsFile"`
+	// Sections maps each KEP status to the body sections a KEP with that
+	// status must have. Statuses that aren't listed use the sections under
+	// default. When empty, the kubernetes/enhancements sections are required.
+	Sections map[string][]string `yaml:"sections"`
+
+	schema *Schema
+	sigs   *SIGRegistry
+}
+
+// KeyRule describes a single metadata key.
+type KeyRule struct {
+	// Type is string, list (of strings) or date (a YYYY-MM-DD string).
+	Type string `yaml:"type"`
+	// Required keys must be present.
+	Required bool `yaml:"required"`
+	// AllowEmpty lets a present key have no value.
+	AllowEmpty bool `yaml:"allowEmpty"`
+	// Enum lists the allowed values. Every item of a list must be allowed.
+	Enum []string `yaml:"enum"`
+	// Pattern is a regular expression every value must match.
+	Pattern string `yaml:"pattern"`
+	// Severity of errors about this key. Defaults to error.
+	Severity string `yaml:"severity"`
+
+	pattern  *regexp.Regexp
+	severity Severity
+}
+
+type MissingKey struct {
+	key string
+}
+
+func (m *MissingKey) Error() string {
+	return fmt.Sprintf("%q is required", m.key)
+}
+
+type ValueNotAllowed struct {
+	key     string
+	value   string
+	allowed []string
+}
+
+func (v *ValueNotAllowed) Error() string {
+	return fmt.Sprintf("%q must be one of %s but it is %q", v.key, strings.Join(v.allowed, ", "), v.value)
+}
+
+type ValueDoesNotMatch struct {
+	key     string
+	value   string
+	pattern string
+}
+
+func (v *ValueDoesNotMatch) Error() string {
+	return fmt.Sprintf("%q must match %q but it is %q", v.key, v.pattern, v.value)
+}
+
+type ValueMustBeDate struct {
+	key   string
+	value string
+}
+
+func (v *ValueMustBeDate) Error() string {
+	return fmt.Sprintf("%q must be a YYYY-MM-DD date but it is %q", v.key, v.value)
+}
+
+// DefaultConfig returns the rules for the KEPs in kubernetes/enhancements.
+func DefaultConfig() *Config {
+	nonEmptyString := func() *KeyRule { return &KeyRule{Type: TypeString} }
+	optionalList := func() *KeyRule { return &KeyRule{Type: TypeList, AllowEmpty: true} }
+	nonEmptyList := func() *KeyRule { return &KeyRule{Type: TypeList} }
+	c := &Config{
+		Keys: map[string]*KeyRule{
+			"title":              nonEmptyString(),
+			"owning-sig":         nonEmptyString(),
+			"status":             nonEmptyString(),
+			"creation-date":      nonEmptyString(),
+			"last-updated":       nonEmptyString(),
+			"editor":             {Type: TypeString, AllowEmpty: true},
+			"authors":            nonEmptyList(),
+			"reviewers":          nonEmptyList(),
+			"approvers":          nonEmptyList(),
+			"participating-sigs": optionalList(),
+			"replaces":           optionalList(),
+			"superseded-by":      optionalList(),
+			"see-also":           optionalList(),
+		},
+		Rules:    map[string]string{},
+		Sections: defaultSections(),
+	}
+	if err := c.compile(); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// LoadConfig reads the configuration file at path. Without a path it reads
+// the one nearest dir, falling back to the default rules.
+func LoadConfig(path, dir string) (*Config, error) {
+	if path == "" {
+		found, ok := FindConfig(dir)
+		if !ok {
+			return DefaultConfig(), nil
+		}
+		path = found
+	}
+	return readConfig(path)
+}
+
+// readConfig reads a configuration file.
+func readConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, errors.Wrapf(err, "error reading %v", path)
+	}
+	if err := c.compile(); err != nil {
+		return nil, errors.Wrapf(err, "error reading %v", path)
+	}
+	if c.SchemaFile != "" {
+		schema, err := LoadSchema(relativeTo(path, c.SchemaFile))
+		if err != nil {
+			return nil, err
+		}
+		c.schema = schema
+	}
+	switch {
+	case c.SIGsFile != "":
+		sigs, err := LoadSIGRegistry(relativeTo(path, c.SIGsFile))
+		if err != nil {
+			return nil, err
+		}
+		c.sigs = sigs
+	case len(c.SIGs) > 0:
+		c.sigs = NewSIGRegistry(c.SIGs...)
+	}
+	return c, nil
+}
+
+// relativeTo resolves a path written in the configuration file at config.
+func relativeTo(config, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(config), path)
+}
+
+// FindConfig looks for a configuration file in dir and its parents.
+func FindConfig(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		path := filepath.Join(dir, ConfigFilename)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// compile checks the configuration and prepares patterns and severities.
+func (c *Config) compile() error {
+	if c.Keys == nil {
+		c.Keys = map[string]*KeyRule{}
+	}
+	if c.Rules == nil {
+		c.Rules = map[string]string{}
+	}
+	keys := map[string]*KeyRule{}
+	for key, rule := range c.Keys {
+		if rule == nil {
+			rule = &KeyRule{}
+		}
+		switch rule.Type {
+		case "":
+			rule.Type = TypeString
+		case TypeString, TypeList, TypeDate:
+		default:
+			return errors.Errorf("key %q has unknown type %q, must be one of string, list or date", key, rule.Type)
+		}
+		if rule.Pattern != "" {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return errors.Wrapf(err, "key %q has a bad pattern", key)
+			}
+			rule.pattern = re
+		}
+		if rule.Severity != "" {
+			severity, err := ParseSeverity(rule.Severity)
+			if err != nil {
+				return errors.Wrapf(err, "key %q", key)
+			}
+			rule.severity = severity
+		}
+		keys[strings.ToLower(key)] = rule
+	}
+	c.Keys = keys
+	if c.Sections != nil {
+		sections := map[string][]string{}
+		for status, titles := range c.Sections {
+			sections[strings.ToLower(status)] = titles
+		}
+		c.Sections = sections
+	}
+	for code, severity := range c.Rules {
+		if severity == SeverityOff {
+			continue
+		}
+		if _, err := ParseSeverity(severity); err != nil {
+			return errors.Wrapf(err, "rule %q", code)
+		}
+	}
+	return nil
+}
+
+// SetSchema sets the JSON Schema the metadata must match, replacing any
+// schema from the configuration file.
+func (c *Config) SetSchema(s *Schema) {
+	c.schema = s
+}
+
+// SetSIGRegistry sets the groups that may own or participate in a KEP,
+// replacing any from the configuration file.
+func (c *Config) SetSIGRegistry(r *SIGRegistry) {
+	c.sigs = r
+}
+
+// CanonicalSIG returns the registry name of a SIG. Without a registry, or
+// for unknown SIGs, the name is only normalised.
+func (c *Config) CanonicalSIG(name string) string {
+	if c.sigs != nil {
+		if canonical, ok := c.sigs.Canonical(name); ok {
+			return canonical
+		}
+	}
+	return normaliseSIG(name)
+}
+
+// ValidateKeys returns every error in the parsed KEP metadata, positioned at
+// the key the error is about and ordered by line. Missing required keys are
+// reported where position puts an unknown key.
+func (c *Config) ValidateKeys(parsed map[interface{}]interface{}, position KeyPosition) ErrorList {
+	var errs ErrorList
+	found := map[string]bool{}
+	for key, value := range parsed {
+		severity, err := c.validateKey(key, value)
+		if k, ok := key.(string); ok {
+			found[strings.ToLower(k)] = true
+		}
+		if err != nil {
+			line, column := position(fmt.Sprint(key))
+			errs = append(errs, &Error{Line: line, Column: column, Severity: severity, Err: err})
+		}
+	}
+	for key, rule := range c.Keys {
+		if rule.Required && !found[key] {
+			line, column := position("")
+			errs = append(errs, &Error{Line: line, Column: column, Severity: rule.severity, Err: &MissingKey{key}})
+		}
+	}
+	if c.schema != nil {
+		errs = append(errs, c.schema.Validate(parsed, position)...)
+	}
+	if c.sigs != nil {
+		errs = append(errs, c.sigs.Validate(parsed, position)...)
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Error() < errs[j].Error()
+	})
+	return c.Apply(errs)
+}
+
+func (c *Config) validateKey(key, value interface{}) (Severity, error) {
+	// First off the key has to be a string. fact.
+	k, ok := key.(string)
+	if !ok {
+		return SeverityError, &KeyMustBeString{key}
+	}
+	rule, ok := c.Keys[strings.ToLower(k)]
+	if !ok {
+		return SeverityError, nil
+	}
+	return rule.severity, rule.validate(k, value)
+}
+
+func (r *KeyRule) validate(key string, value interface{}) error {
+	if r.Type == TypeList {
+		if value == nil {
+			if r.AllowEmpty {
+				return nil
+			}
+			return &MustHaveAtLeastOneValue{key}
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return &ValueMustBeListOfStrings{key, value}
+		}
+		if len(items) == 0 && !r.AllowEmpty {
+			return &MustHaveAtLeastOneValue{key}
+		}
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return &ValueMustBeListOfStrings{key, value}
+			}
+			if err := r.validateValue(key, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if value == nil {
+		if r.AllowEmpty {
+			return nil
+		}
+		return &MustHaveOneValue{key}
+	}
+	s, ok := value.(string)
+	if !ok {
+		return &ValueMustBeString{key, value}
+	}
+	if s == "" {
+		if r.AllowEmpty {
+			return nil
+		}
+		return &MustHaveOneValue{key}
+	}
+	return r.validateValue(key, s)
+}
+
+func (r *KeyRule) validateValue(key, value string) error {
+	if r.Type == TypeDate {
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return &ValueMustBeDate{key, value}
+		}
+	}
+	if len(r.Enum) > 0 {
+		allowed := false
+		for _, e := range r.Enum {
+			if e == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return &ValueNotAllowed{key, value, r.Enum}
+		}
+	}
+	if r.pattern != nil && !r.pattern.MatchString(value) {
+		return &ValueDoesNotMatch{key, value, r.Pattern}
+	}
+	return nil
+}
+
+// Apply sets the severity of each error from the configured rules and drops
+// errors whose rule is turned off.
+func (c *Config) Apply(errs ErrorList) ErrorList {
+	if len(c.Rules) == 0 {
+		return errs
+	}
+	// errs may be a KEP's own error list, so neither it nor its errors are
+	// changed.
+	out := make(ErrorList, 0, len(errs))
+	for _, err := range errs {
+		severity, ok := c.Rules[err.Code()]
+		if !ok {
+			out = append(out, err)
+			continue
+		}
+		if severity == SeverityOff {
+			continue
+		}
+		changed := *err
+		changed.Severity, _ = ParseSeverity(severity)
+		out = append(out, &changed)
+	}
+	return out
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+const testConfig = `keys:
+  title:
+    required: true
+  stage:
+    enum: [alpha, beta, stable]
+  tracking-issue:
+    pattern: '^#[0-9]+$'
+    severity: warning
+  created:
+    type: date
+  owners:
+    type: list
+rules:
+  missing-section: off
+  stale-toc: info
+`
+
+func writeConfig(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "kepval-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, ConfigFilename)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeConfig(t, testConfig)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	c, err := LoadConfig(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name     string
+		doc      string
+		codes    []string
+		severity Severity
+	}{
+		{
+			name: "valid",
+			doc:  "title: t\nstage: beta\ntracking-issue: '#12'\ncreated: 2019-01-02\nowners: [a]\n",
+		},
+		{
+			name:  "missing required key",
+			doc:   "stage: beta\n",
+			codes: []string{"missing-key"},
+		},
+		{
+			name:  "value not in enum",
+			doc:   "title: t\nstage: gamma\n",
+			codes: []string{"value-not-allowed"},
+		},
+		{
+			name:     "pattern with a severity",
+			doc:      "title: t\ntracking-issue: twelve\n",
+			codes:    []string{"value-does-not-match"},
+			severity: SeverityWarning,
+		},
+		{
+			name:  "bad date",
+			doc:   "title: t\ncreated: yesterday\n",
+			codes: []string{"value-must-be-date"},
+		},
+		{
+			name: "unknown keys are allowed",
+			doc:  "title: t\nanything: [1, 2]\n",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := map[interface{}]interface{}{}
+			if err := yaml.Unmarshal([]byte(tc.doc), p); err != nil {
+				t.Fatal(err)
+			}
+			errs := c.ValidateKeys(p, func(string) (int, int) { return 1, 1 })
+			if len(errs) != len(tc.codes) {
+				t.Fatalf("expected %v but got %v", tc.codes, errs)
+			}
+			for i, code := range tc.codes {
+				if errs[i].Code() != code {
+					t.Fatalf("expected %q but got %q", code, errs[i].Code())
+				}
+				if errs[i].Severity != tc.severity {
+					t.Fatalf("expected severity %v but got %v", tc.severity, errs[i].Severity)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	testcases := []struct {
+		name    string
+		content string
+	}{
+		{name: "unknown type", content: "keys:\n  title:\n    type: number\n"},
+		{name: "bad pattern", content: "keys:\n  title:\n    pattern: '['\n"},
+		{name: "bad severity", content: "rules:\n  stale-toc: fatal\n"},
+		{name: "unknown field", content: "keyz: {}\n"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeConfig(t, tc.content)
+			defer os.RemoveAll(filepath.Dir(path))
+			if _, err := LoadConfig(path, ""); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	path := writeConfig(t, testConfig)
+	defer os.RemoveAll(filepath.Dir(path))
+	c, err := LoadConfig(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := ErrorList{
+		{Line: 1, Err: &MissingSection{}},
+		{Line: 2, Err: &StaleTableOfContents{}},
+		{Line: 3, Err: &MissingKey{"title"}},
+	}
+	errs := c.Apply(in)
+	if in[0].Line != 1 || in[1].Line != 2 || in[1].Severity != SeverityError {
+		t.Fatalf("expected the errors passed in to be left alone but got %v", in)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected missing-section to be dropped but got %v", errs)
+	}
+	if errs[0].Severity != SeverityInfo || errs[1].Severity != SeverityError {
+		t.Fatalf("unexpected severities %v and %v", errs[0].Severity, errs[1].Severity)
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	path := writeConfig(t, "")
+	defer os.RemoveAll(filepath.Dir(path))
+	nested := filepath.Join(filepath.Dir(path), "keps", "sig-foo")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	found, ok := FindConfig(nested)
+	if !ok || found != path {
+		t.Fatalf("expected to find %v but got %v", path, found)
+	}
+}
+
+func TestLoadNearestConfig(t *testing.T) {
+	path := writeConfig(t, testConfig)
+	defer os.RemoveAll(filepath.Dir(path))
+	nested := filepath.Join(filepath.Dir(path), "keps", "sig-foo")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	c, err := LoadConfig("", nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Rules) == 0 {
+		t.Fatalf("expected the rules in %v but got %v", path, c.Rules)
+	}
+
+	empty, err := ioutil.TempDir("", "kepval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(empty)
+	c, err = LoadConfig("", empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Rules) != 0 || c.Keys["title"] == nil {
+		t.Fatalf("expected the default rules but got %v", c)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is how serious a validation error is. The zero value is an error.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	default:
+		return "error"
+	}
+}
+
+// ParseSeverity parses "error", "warning" or "info".
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(s) {
+	case "error":
+		return SeverityError, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "info":
+		return SeverityInfo, nil
+	}
+	return SeverityError, fmt.Errorf("unknown severity %q, must be one of error, warning or info", s)
+}
+
+// Error is a validation error found on a line of a KEP file. Column is 0
+// when the error applies to the whole line.
+type Error struct {
+	Line     int
+	Column   int
+	Severity Severity
+	Err      error
+}
+
+func (e *Error) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Cause returns the underlying validation error.
+func (e *Error) Cause() error {
+	return e.Err
+}
+
+// Code returns the identifier of the rule that produced the error.
+func (e *Error) Code() string {
+	return Code(e.Err)
+}
+
+// ErrorList is every validation error found in a KEP.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, 0, len(l))
+	for _, err := range l {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// HasAtLeast returns true if any error is at least as severe as s.
+func (l ErrorList) HasAtLeast(s Severity) bool {
+	for _, err := range l {
+		if err.Severity <= s {
+			return true
+		}
+	}
+	return false
+}
+
+type InvalidYAML struct {
+	message string
+}
+
+func (i *InvalidYAML) Error() string {
+	return i.message
+}
+
+// Code returns the stable identifier of the rule behind a validation error,
+// for use in machine-readable output.
+func Code(err error) string {
+	switch err.(type) {
+	case *KeyMustBeString:
+		return "key-must-be-string"
+	case *ValueMustBeString:
+		return "value-must-be-string"
+	case *ValueMustBeListOfStrings:
+		return "value-must-be-list-of-strings"
+	case *MustHaveOneValue:
+		return "must-have-one-value"
+	case *MustHaveAtLeastOneValue:
+		return "must-have-at-least-one-value"
+	case *MissingKey:
+		return "missing-key"
+	case *ValueNotAllowed:
+		return "value-not-allowed"
+	case *ValueDoesNotMatch:
+		return "value-does-not-match"
+	case *ValueMustBeDate:
+		return "value-must-be-date"
+	case *InvalidYAML:
+		return "invalid-yaml"
+	case *MissingSection:
+		return "missing-section"
+	case *PlaceholderSection:
+		return "placeholder-section"
+	case *StaleTableOfContents:
+		return "stale-toc"
+	case *BrokenLink:
+		return "broken-link"
+	case *InvalidStatusTransition:
+		return "invalid-status-transition"
+	case *LastUpdatedNotBumped:
+		return "last-updated-not-bumped"
+	case *MissingEditor:
+		return "missing-editor"
+	case *StaleLastUpdated:
+		return "stale-last-updated"
+	case *PlaceholderValue:
+		return "placeholder-value"
+	case *InvalidHandle:
+		return "invalid-handle"
+	case *DuplicatePerson:
+		return "duplicate-person"
+	case *PersonInSeveralLists:
+		return "person-in-several-lists"
+	case *ApproverLacksRights:
+		return "approver-lacks-rights"
+	case *UnknownSIG:
+		return "unknown-sig"
+	case *SchemaViolation:
+		return "schema-violation"
+	case *InvalidSuppression:
+		return "invalid-suppression"
+	case *UnusedSuppression:
+		return "unused-suppression"
+	case *SuppressionWithoutReason:
+		return "suppression-without-reason"
+	}
+	return "invalid-kep"
+}
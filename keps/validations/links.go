@@ -0,0 +1,42 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"fmt"
+)
+
+type BrokenLink struct {
+	url    string
+	reason string
+}
+
+func (b *BrokenLink) Error() string {
+	return fmt.Sprintf("broken link %q: %s", b.url, b.reason)
+}
+
+// LinkResolver looks up the target of a link. It returns false and the reason
+// when the target cannot be found.
+type LinkResolver func(url string) (string, bool)
+
+// ValidateLink returns an error if resolve cannot find the target of url.
+func ValidateLink(url string, resolve LinkResolver) error {
+	if reason, ok := resolve(url); !ok {
+		return &BrokenLink{url, reason}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import "fmt"
+
+type ApproverLacksRights struct {
+	handle string
+	dir    string
+}
+
+func (a *ApproverLacksRights) Error() string {
+	return fmt.Sprintf("@%s is not an approver in the OWNERS files for %s", a.handle, a.dir)
+}
+
+// ValidateApprovers returns a warning for every approver who can't approve
+// changes to dir according to its OWNERS files. Entries that aren't GitHub
+// handles are left to other rules.
+func ValidateApprovers(approvers []string, allowed map[string]bool, dir string, line, column int) ErrorList {
+	var errs ErrorList
+	for _, approver := range approvers {
+		handle, _, ok := ParsePerson(approver)
+		if !ok || allowed[handle] {
+			continue
+		}
+		errs = append(errs, &Error{Line: line, Column: column, Severity: SeverityWarning, Err: &ApproverLacksRights{handle, dir}})
+	}
+	return errs
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PeopleKeys are the metadata keys that list GitHub users.
+var PeopleKeys = []string{"authors", "reviewers", "approvers"}
+
+// A GitHub username has up to 39 letters, digits and single dashes and
+// doesn't start or end with a dash. A team is written org/team, where the team
+// slug may also use underscores.
+var (
+	handleRe      = regexp.MustCompile(`^@?([a-zA-Z0-9](?:[a-zA-Z0-9]|-[a-zA-Z0-9]){0,38}(?:/[a-zA-Z0-9][a-zA-Z0-9_-]*)?)$`)
+	displayNameRe = regexp.MustCompile(`^(.*\S)\s*\(\s*(@?[^()\s]+)\s*\)$`)
+)
+
+type InvalidHandle struct {
+	key   string
+	value string
+}
+
+func (i *InvalidHandle) Error() string {
+	return fmt.Sprintf("%q has %q, which is not a GitHub handle like @jane, @org/team or \"Jane Doe (@jane)\"", i.key, i.value)
+}
+
+type DuplicatePerson struct {
+	key    string
+	handle string
+}
+
+func (d *DuplicatePerson) Error() string {
+	return fmt.Sprintf("%q lists @%s more than once", d.key, d.handle)
+}
+
+type PersonInSeveralLists struct {
+	handle string
+	keys   []string
+}
+
+func (p *PersonInSeveralLists) Error() string {
+	return fmt.Sprintf("@%s is in %s", p.handle, strings.Join(p.keys, " and "))
+}
+
+// ParsePerson reads a GitHub user written as @jane, jane or
+// "Jane Doe (@jane)", or a team written as @org/team. The handle is returned
+// lower cased without the @. The TBD placeholder is not a person.
+func ParsePerson(s string) (handle, name string, ok bool) {
+	s = strings.TrimSpace(s)
+	if isPlaceholder(s) {
+		return "", "", false
+	}
+	if m := displayNameRe.FindStringSubmatch(s); m != nil {
+		name, s = m[1], m[2]
+	}
+	m := handleRe.FindStringSubmatch(s)
+	if m == nil {
+		return "", "", false
+	}
+	return strings.ToLower(m[1]), name, true
+}
+
+// ValidatePeople returns a warning for every entry in authors, reviewers and
+// approvers that isn't a GitHub handle or is listed twice, and for every handle
+// in more than one of the lists. Projects can make them errors with rules in
+// their configuration. TBD placeholders are left to other rules.
+func ValidatePeople(parsed map[interface{}]interface{}, position KeyPosition) ErrorList {
+	var errs ErrorList
+	add := func(key string, severity Severity, err error) {
+		line, column := position(key)
+		errs = append(errs, &Error{Line: line, Column: column, Severity: severity, Err: err})
+	}
+	lists := map[string][]string{}
+	var order []string
+	for _, key := range PeopleKeys {
+		items, ok := parsed[key].([]interface{})
+		if !ok {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok || isPlaceholder(s) {
+				continue
+			}
+			handle, _, ok := ParsePerson(s)
+			if !ok {
+				add(key, SeverityWarning, &InvalidHandle{key, s})
+				continue
+			}
+			if seen[handle] {
+				add(key, SeverityWarning, &DuplicatePerson{key, handle})
+				continue
+			}
+			seen[handle] = true
+			if _, ok := lists[handle]; !ok {
+				order = append(order, handle)
+			}
+			lists[handle] = append(lists[handle], key)
+		}
+	}
+	for _, handle := range order {
+		if keys := lists[handle]; len(keys) > 1 {
+			add(keys[1], SeverityWarning, &PersonInSeveralLists{handle, keys})
+		}
+	}
+	return errs
+}
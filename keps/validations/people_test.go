@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestParsePerson(t *testing.T) {
+	testcases := []struct {
+		in     string
+		handle string
+		name   string
+		ok     bool
+	}{
+		{"@jane", "jane", "", true},
+		{"JaneDoe", "janedoe", "", true},
+		{"Jane Doe (@jane-doe)", "jane-doe", "Jane Doe", true},
+		{"Jane Doe(jane)", "jane", "Jane Doe", true},
+		{"Jane Doe", "", "", false},
+		{"@-jane", "", "", false},
+		{"@jane--doe", "", "", false},
+		{"@a234567890123456789012345678901234567890", "", "", false},
+		{"TBD", "", "", false},
+		{"@kubernetes/sig-node-leads", "kubernetes/sig-node-leads", "", true},
+		{"Node leads (@Kubernetes/Node_Leads)", "kubernetes/node_leads", "Node leads", true},
+		{"@kubernetes/", "", "", false},
+		{"@kubernetes/sig/node", "", "", false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.in, func(t *testing.T) {
+			handle, name, ok := ParsePerson(tc.in)
+			if handle != tc.handle || name != tc.name || ok != tc.ok {
+				t.Fatalf("expected (%q, %q, %v) but got (%q, %q, %v)", tc.handle, tc.name, tc.ok, handle, name, ok)
+			}
+		})
+	}
+}
+
+func TestValidatePeople(t *testing.T) {
+	doc := `authors:
+  - "@jane"
+  - Jane Doe
+  - "@Jane"
+reviewers:
+  - TBD
+  - Bob (@bob)
+approvers:
+  - "@bob"
+`
+	p := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(doc), p); err != nil {
+		t.Fatal(err)
+	}
+	lines := map[string]int{"authors": 2, "reviewers": 6, "approvers": 9}
+	errs := ValidatePeople(p, func(key string) (int, int) { return lines[key], 1 })
+	expected := []struct {
+		code     string
+		severity Severity
+		line     int
+	}{
+		{"invalid-handle", SeverityWarning, 2},
+		{"duplicate-person", SeverityWarning, 2},
+		{"person-in-several-lists", SeverityWarning, 9},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors but got %v", len(expected), errs)
+	}
+	for i, e := range expected {
+		if errs[i].Code() != e.code || errs[i].Severity != e.severity || errs[i].Line != e.line {
+			t.Fatalf("expected %v but got %v %v %v", e, errs[i].Code(), errs[i].Severity, errs[i].Line)
+		}
+	}
+}
@@ -0,0 +1,708 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// SchemaDialect is the JSON Schema draft KEP schemas are written in.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema used to describe and validate KEP
+// metadata. A boolean schema is a Schema that only has Bool set.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	ID          string             `json:"$id,omitempty"`
+	Ref         string             `json:"$ref,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
+	Definitions map[string]*Schema `json:"definitions,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+
+	Type             SchemaTypes   `json:"type,omitempty"`
+	Enum             []interface{} `json:"enum,omitempty"`
+	Const            interface{}   `json:"const,omitempty"`
+	Format           string        `json:"format,omitempty"`
+	Pattern          string        `json:"pattern,omitempty"`
+	MinLength        *int          `json:"minLength,omitempty"`
+	MaxLength        *int          `json:"maxLength,omitempty"`
+	Minimum          *float64      `json:"minimum,omitempty"`
+	Maximum          *float64      `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64      `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64      `json:"exclusiveMaximum,omitempty"`
+
+	Items       *Schema `json:"items,omitempty"`
+	MinItems    *int    `json:"minItems,omitempty"`
+	MaxItems    *int    `json:"maxItems,omitempty"`
+	UniqueItems bool    `json:"uniqueItems,omitempty"`
+
+	Properties           map[string]*Schema  `json:"properties,omitempty"`
+	PatternProperties    map[string]*Schema  `json:"patternProperties,omitempty"`
+	AdditionalProperties *Schema             `json:"additionalProperties,omitempty"`
+	Required             []string            `json:"required,omitempty"`
+	DependentRequired    map[string][]string `json:"dependentRequired,omitempty"`
+
+	AllOf []*Schema `json:"allOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	Not   *Schema   `json:"not,omitempty"`
+	If    *Schema   `json:"if,omitempty"`
+	Then  *Schema   `json:"then,omitempty"`
+	Else  *Schema   `json:"else,omitempty"`
+
+	// Bool is set for the boolean schemas true, which allows anything, and
+	// false, which allows nothing.
+	Bool *bool `json:"-"`
+
+	pattern           *regexp.Regexp
+	patternProperties map[string]*regexp.Regexp
+	ref               *Schema
+}
+
+// schemaFields has the same fields as Schema without its JSON methods.
+type schemaFields Schema
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.Bool != nil {
+		return json.Marshal(*s.Bool)
+	}
+	return json.Marshal((*schemaFields)(s))
+}
+
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*s = Schema{Bool: &b}
+		return nil
+	}
+	// Keywords the validator doesn't know would otherwise be dropped, and a
+	// schema relying on them would pass everything.
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+	for keyword := range keywords {
+		if !schemaKeywords[keyword] && !annotationKeywords[keyword] {
+			return errors.Errorf("unsupported keyword %q", keyword)
+		}
+	}
+	return json.Unmarshal(data, (*schemaFields)(s))
+}
+
+// schemaKeywords are the keywords Schema has a field for.
+var schemaKeywords = func() map[string]bool {
+	keywords := map[string]bool{}
+	t := reflect.TypeOf(schemaFields{})
+	for i := 0; i < t.NumField(); i++ {
+		if name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+			keywords[name] = true
+		}
+	}
+	return keywords
+}()
+
+// annotationKeywords only describe a schema, so they're accepted and ignored.
+var annotationKeywords = map[string]bool{
+	"$comment":   true,
+	"default":    true,
+	"deprecated": true,
+	"examples":   true,
+	"readOnly":   true,
+	"writeOnly":  true,
+}
+
+// SchemaTypes is the value of the type keyword, a single type or a list.
+type SchemaTypes []string
+
+func (t SchemaTypes) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *SchemaTypes) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = SchemaTypes{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+// Schema describes the keys in the configuration as JSON Schema properties.
+// Keys missing from properties are added.
+func (c *Config) Schema(properties map[string]*Schema) *Schema {
+	one := 1
+	s := &Schema{
+		Schema:     SchemaDialect,
+		Type:       SchemaTypes{"object"},
+		Properties: properties,
+	}
+	if s.Properties == nil {
+		s.Properties = map[string]*Schema{}
+	}
+	for key, rule := range c.Keys {
+		property, ok := s.Properties[key]
+		if !ok {
+			property = &Schema{}
+			s.Properties[key] = property
+		}
+		// Keys that may be empty may also be written without a value.
+		property.Type = nil
+		value := property
+		if rule.Type == TypeList {
+			property.Type = SchemaTypes{"array"}
+			if property.Items == nil {
+				property.Items = &Schema{}
+			}
+			value = property.Items
+			if !rule.AllowEmpty {
+				property.MinItems = &one
+			}
+		} else {
+			property.Items = nil
+			if !rule.AllowEmpty {
+				value.MinLength = &one
+			}
+		}
+		value.Type = append(value.Type[:0], "string")
+		if rule.AllowEmpty {
+			property.Type = append(property.Type, "null")
+		}
+		if rule.Type == TypeDate {
+			value.Format = "date"
+		}
+		for _, e := range rule.Enum {
+			value.Enum = append(value.Enum, e)
+		}
+		value.Pattern = rule.Pattern
+		if rule.Required {
+			s.Required = append(s.Required, key)
+		}
+	}
+	sort.Strings(s.Required)
+	return s
+}
+
+type SchemaViolation struct {
+	path    string
+	message string
+}
+
+func (s *SchemaViolation) Error() string {
+	if s.path == "" {
+		return s.message
+	}
+	return fmt.Sprintf("%s: %s", s.path, s.message)
+}
+
+// LoadSchema reads a JSON Schema from a JSON or YAML file.
+func LoadSchema(path string) (*Schema, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, errors.Wrapf(err, "error reading %v", path)
+		}
+		if data, err = json.Marshal(jsonValue(doc)); err != nil {
+			return nil, errors.Wrapf(err, "error reading %v", path)
+		}
+	}
+	s := &Schema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, errors.Wrapf(err, "error reading %v", path)
+	}
+	if err := s.compile(s); err != nil {
+		return nil, errors.Wrapf(err, "error reading %v", path)
+	}
+	if err := s.checkCycles(); err != nil {
+		return nil, errors.Wrapf(err, "error reading %v", path)
+	}
+	return s, nil
+}
+
+// compile prepares the patterns and references of a schema and its
+// subschemas.
+func (s *Schema) compile(root *Schema) error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return errors.Wrapf(err, "bad pattern %q", s.Pattern)
+		}
+		s.pattern = re
+	}
+	if len(s.PatternProperties) > 0 {
+		s.patternProperties = map[string]*regexp.Regexp{}
+		for p := range s.PatternProperties {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return errors.Wrapf(err, "bad pattern %q", p)
+			}
+			s.patternProperties[p] = re
+		}
+	}
+	if s.ID != "" && s != root {
+		// An $id changes what references below it are relative to.
+		return errors.Errorf("unsupported $id %q, only the root schema may have one", s.ID)
+	}
+	if s.Ref != "" {
+		ref, err := root.resolve(s.Ref)
+		if err != nil {
+			return err
+		}
+		s.ref = ref
+	}
+	for _, sub := range s.subschemas() {
+		if err := sub.compile(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subschemas returns the schemas written inside s.
+func (s *Schema) subschemas() []*Schema {
+	var subschemas []*Schema
+	for _, m := range []map[string]*Schema{s.Defs, s.Definitions, s.Properties, s.PatternProperties} {
+		for _, sub := range m {
+			subschemas = append(subschemas, sub)
+		}
+	}
+	subschemas = append(subschemas, s.AllOf...)
+	subschemas = append(subschemas, s.AnyOf...)
+	subschemas = append(subschemas, s.OneOf...)
+	return append(subschemas, s.Items, s.AdditionalProperties, s.Not, s.If, s.Then, s.Else)
+}
+
+// checkCycles returns an error if a compiled schema's references lead back to
+// a schema that applies to the same value, such as a definition that is only
+// a $ref to itself. Validating it would never finish.
+func (s *Schema) checkCycles() error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[*Schema]int{}
+	// visit follows the references and applicators that check the same value
+	// as s. Properties and items check a smaller value, so they end a cycle.
+	var visit func(s *Schema, ref string) error
+	visit = func(s *Schema, ref string) error {
+		if s == nil || state[s] == done {
+			return nil
+		}
+		if state[s] == visiting {
+			return errors.Errorf("$ref %q refers back to itself without checking a property or item", ref)
+		}
+		state[s] = visiting
+		if s.ref != nil {
+			if err := visit(s.ref, s.Ref); err != nil {
+				return err
+			}
+		}
+		inPlace := append(append(append([]*Schema{s.Not, s.If, s.Then, s.Else}, s.AllOf...), s.AnyOf...), s.OneOf...)
+		for _, sub := range inPlace {
+			if err := visit(sub, ref); err != nil {
+				return err
+			}
+		}
+		state[s] = done
+		return nil
+	}
+	var walk func(s *Schema) error
+	walk = func(s *Schema) error {
+		if s == nil {
+			return nil
+		}
+		if err := visit(s, s.Ref); err != nil {
+			return err
+		}
+		for _, sub := range s.subschemas() {
+			if err := walk(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(s)
+}
+
+// resolve finds the schema a local reference such as #/$defs/person points at.
+func (s *Schema) resolve(ref string) (*Schema, error) {
+	if ref == "#" {
+		return s, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+	if !strings.HasPrefix(ref, "#/") || len(parts) != 2 {
+		return nil, errors.Errorf("unsupported $ref %q, only #/$defs/<name> references are supported", ref)
+	}
+	var defs map[string]*Schema
+	switch parts[0] {
+	case "$defs":
+		defs = s.Defs
+	case "definitions":
+		defs = s.Definitions
+	default:
+		return nil, errors.Errorf("unsupported $ref %q, only #/$defs/<name> references are supported", ref)
+	}
+	name := strings.NewReplacer("~1", "/", "~0", "~").Replace(parts[1])
+	def, ok := defs[name]
+	if !ok {
+		return nil, errors.Errorf("$ref %q points at nothing", ref)
+	}
+	return def, nil
+}
+
+// Validate returns every way the parsed KEP metadata breaks the schema,
+// positioned at the top level key each problem is in.
+func (s *Schema) Validate(parsed map[interface{}]interface{}, position KeyPosition) ErrorList {
+	var violations []*SchemaViolation
+	s.validate(jsonValue(parsed), "", &violations)
+	var errs ErrorList
+	for _, v := range violations {
+		key := ""
+		if v.path != "" {
+			key = strings.Split(strings.TrimPrefix(v.path, "/"), "/")[0]
+		}
+		line, column := position(key)
+		errs = append(errs, &Error{Line: line, Column: column, Err: v})
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line
+	})
+	return errs
+}
+
+// valid returns true if value matches the schema.
+func (s *Schema) valid(value interface{}, path string) bool {
+	var violations []*SchemaViolation
+	s.validate(value, path, &violations)
+	return len(violations) == 0
+}
+
+func (s *Schema) validate(value interface{}, path string, out *[]*SchemaViolation) {
+	if s == nil {
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*out = append(*out, &SchemaViolation{path, fmt.Sprintf(format, args...)})
+	}
+	if s.Bool != nil {
+		if !*s.Bool {
+			fail("is not allowed")
+		}
+		return
+	}
+	if s.ref != nil {
+		s.ref.validate(value, path, out)
+	}
+
+	if len(s.Type) > 0 {
+		matched := false
+		for _, t := range s.Type {
+			if hasType(value, t) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("must be %s but it is %s", strings.Join(s.Type, " or "), typeName(value))
+			return
+		}
+	}
+	if len(s.Enum) > 0 {
+		allowed := false
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(jsonValue(e), value) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			fail("must be one of %s but it is %s", formatValues(s.Enum), formatValue(value))
+		}
+	}
+	if s.Const != nil && !reflect.DeepEqual(jsonValue(s.Const), value) {
+		fail("must be %s but it is %s", formatValue(s.Const), formatValue(value))
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			if *s.MinLength == 1 {
+				fail("must not be empty")
+			} else {
+				fail("must be at least %d characters long", *s.MinLength)
+			}
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("must be at most %d characters long", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match %q but it is %q", s.Pattern, v)
+		}
+		if !validFormat(s.Format, v) {
+			fail("must be a %s but it is %q", s.Format, v)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("must be at most %v", *s.Maximum)
+		}
+		if s.ExclusiveMinimum != nil && v <= *s.ExclusiveMinimum {
+			fail("must be more than %v", *s.ExclusiveMinimum)
+		}
+		if s.ExclusiveMaximum != nil && v >= *s.ExclusiveMaximum {
+			fail("must be less than %v", *s.ExclusiveMaximum)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			if *s.MinItems == 1 {
+				fail("must have at least one item")
+			} else {
+				fail("must have at least %d items", *s.MinItems)
+			}
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("must have at most %d items", *s.MaxItems)
+		}
+		if s.UniqueItems {
+			for i := range v {
+				for j := 0; j < i; j++ {
+					if reflect.DeepEqual(v[i], v[j]) {
+						fail("must not repeat %s", formatValue(v[i]))
+					}
+				}
+			}
+		}
+		for i, item := range v {
+			s.Items.validate(item, fmt.Sprintf("%s/%d", path, i), out)
+		}
+	case map[string]interface{}:
+		for _, key := range s.Required {
+			if _, ok := v[key]; !ok {
+				fail("%q is required", key)
+			}
+		}
+		for key, required := range s.DependentRequired {
+			if _, ok := v[key]; !ok {
+				continue
+			}
+			for _, r := range required {
+				if _, ok := v[r]; !ok {
+					fail("%q is required when %q is set", r, key)
+				}
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+			matched := false
+			if property, ok := s.Properties[key]; ok {
+				matched = true
+				property.validate(v[key], child, out)
+			}
+			for p, re := range s.patternProperties {
+				if re.MatchString(key) {
+					matched = true
+					s.PatternProperties[p].validate(v[key], child, out)
+				}
+			}
+			if !matched && s.AdditionalProperties != nil {
+				if s.AdditionalProperties.Bool != nil && !*s.AdditionalProperties.Bool {
+					*out = append(*out, &SchemaViolation{child, "is not an allowed key"})
+					continue
+				}
+				s.AdditionalProperties.validate(v[key], child, out)
+			}
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(value, path, out)
+	}
+	if len(s.AnyOf) > 0 {
+		matched := false
+		for _, sub := range s.AnyOf {
+			if sub.valid(value, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("must match at least one of the anyOf schemas")
+		}
+	}
+	if len(s.OneOf) > 0 {
+		matches := 0
+		for _, sub := range s.OneOf {
+			if sub.valid(value, path) {
+				matches++
+			}
+		}
+		if matches != 1 {
+			fail("must match exactly one of the oneOf schemas but matches %d", matches)
+		}
+	}
+	if s.Not != nil && s.Not.valid(value, path) {
+		fail("must not match the not schema")
+	}
+	if s.If != nil {
+		if s.If.valid(value, path) {
+			s.Then.validate(value, path, out)
+		} else {
+			s.Else.validate(value, path, out)
+		}
+	}
+}
+
+// jsonValue converts YAML values to the values encoding/json produces so
+// schemas see the same data model either way.
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[fmt.Sprint(key)] = jsonValue(item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = jsonValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = jsonValue(item)
+		}
+		return out
+	case []string:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = item
+		}
+		return out
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case time.Time:
+		return v.Format("2006-01-02")
+	}
+	return value
+}
+
+func hasType(value interface{}, t string) bool {
+	switch t {
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	}
+	return typeName(value) == t
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func formatValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func formatValues(values []interface{}) string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, formatValue(v))
+	}
+	return strings.Join(out, ", ")
+}
+
+var emailRe = regexp.MustCompile(`^[^@\s]+@[^@\s]+$`)
+
+// validFormat checks the formats KEP metadata uses. Other formats are
+// annotations and always pass.
+func validFormat(format, value string) bool {
+	switch format {
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "email":
+		return emailRe.MatchString(value)
+	}
+	return true
+}
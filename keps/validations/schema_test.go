@@ -0,0 +1,245 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+const testSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "required": ["title", "status"],
+  "additionalProperties": false,
+  "properties": {
+    "title": {"type": "string", "minLength": 1, "$comment": "annotations are ignored", "examples": ["Kubelet"]},
+    "status": {"enum": ["provisional", "implementable", "implemented"]},
+    "authors": {"type": "array", "items": {"$ref": "#/$defs/handle"}, "minItems": 1, "uniqueItems": true},
+    "creation-date": {"type": "string", "format": "date"},
+    "kep-number": {"type": "integer", "minimum": 1}
+  },
+  "$defs": {
+    "handle": {"type": "string", "pattern": "^@[a-zA-Z0-9-]+$"}
+  }
+}`
+
+func writeSchema(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "kepval-schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSchemaValidate(t *testing.T) {
+	path := writeSchema(t, "kep.schema.json", testSchema)
+	defer os.RemoveAll(filepath.Dir(path))
+	schema, err := LoadSchema(path)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	testcases := []struct {
+		name     string
+		doc      string
+		expected []string
+	}{
+		{
+			name: "valid",
+			doc:  "title: t\nstatus: provisional\nauthors: ['@a', '@b']\ncreation-date: 2019-01-02\nkep-number: 12\n",
+		},
+		{
+			name:     "missing required key",
+			doc:      "title: t\n",
+			expected: []string{`"status" is required`},
+		},
+		{
+			name:     "enum",
+			doc:      "title: t\nstatus: done\n",
+			expected: []string{`/status: must be one of "provisional", "implementable", "implemented" but it is "done"`},
+		},
+		{
+			name:     "reference and unique items",
+			doc:      "title: t\nstatus: provisional\nauthors: ['@a', 'b', '@a']\n",
+			expected: []string{`/authors: must not repeat "@a"`, `/authors/1: must match "^@[a-zA-Z0-9-]+$" but it is "b"`},
+		},
+		{
+			name:     "format and integer",
+			doc:      "title: t\nstatus: provisional\ncreation-date: soon\nkep-number: 1.5\n",
+			expected: []string{`/creation-date: must be a date but it is "soon"`, `/kep-number: must be integer but it is number`},
+		},
+		{
+			name:     "additional properties",
+			doc:      "title: t\nstatus: provisional\nextra: 1\n",
+			expected: []string{`/extra: is not an allowed key`},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := map[interface{}]interface{}{}
+			if err := yaml.Unmarshal([]byte(tc.doc), p); err != nil {
+				t.Fatal(err)
+			}
+			lines := map[string]int{}
+			for i, line := range strings.Split(tc.doc, "\n") {
+				lines[strings.Split(line, ":")[0]] = i + 2
+			}
+			errs := schema.Validate(p, func(key string) (int, int) { return lines[key], 1 })
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("expected %v but got %v", tc.expected, errs)
+			}
+			for i, e := range tc.expected {
+				if errs[i].Err.Error() != e || errs[i].Code() != "schema-violation" {
+					t.Fatalf("expected %q but got %q", e, errs[i].Err.Error())
+				}
+			}
+			if tc.name == "enum" && errs[0].Line != 3 {
+				t.Fatalf("expected the error on the status line but got %d", errs[0].Line)
+			}
+		})
+	}
+}
+
+func TestLoadSchemaErrors(t *testing.T) {
+	testcases := []struct {
+		name     string
+		filename string
+		content  string
+		expected string
+	}{
+		{name: "not json", content: "{"},
+		{name: "bad pattern", content: `{"pattern": "["}`},
+		{name: "remote reference", content: `{"$ref": "https://example.com/kep.json"}`},
+		{name: "missing definition", content: `{"$ref": "#/$defs/nothing"}`},
+		{name: "reference to a property", content: `{"$ref": "#/properties/title", "properties": {"title": {}}}`},
+		{name: "nested reference", content: `{"$ref": "#/$defs/a/properties/b", "$defs": {"a": {"properties": {"b": {}}}}}`},
+		{name: "anchor reference", content: `{"$ref": "#handle", "$defs": {"handle": {"$anchor": "handle"}}}`},
+		{name: "nested id", content: `{"properties": {"title": {"$id": "https://example.com/title.json"}}}`},
+		{
+			name:     "definition referring to itself",
+			content:  `{"properties": {"title": {"$ref": "#/$defs/a"}}, "$defs": {"a": {"$ref": "#/$defs/a"}}}`,
+			expected: `$ref "#/$defs/a" refers back to itself`,
+		},
+		{
+			name:     "root referring to itself",
+			content:  `{"$ref": "#"}`,
+			expected: `$ref "#" refers back to itself`,
+		},
+		{
+			name:     "cycle through allOf",
+			content:  `{"$defs": {"a": {"allOf": [{"$ref": "#/$defs/b"}]}, "b": {"anyOf": [{"$ref": "#/$defs/a"}]}}}`,
+			expected: "refers back to itself",
+		},
+		{
+			name:     "unsupported keyword",
+			content:  `{"type": "object", "propertyNames": {"pattern": "^[a-z-]+$"}}`,
+			expected: `unsupported keyword "propertyNames"`,
+		},
+		{
+			name:     "unsupported nested keyword",
+			content:  `{"properties": {"authors": {"type": "array", "prefixItems": [{"type": "string"}]}}}`,
+			expected: `unsupported keyword "prefixItems"`,
+		},
+		{
+			name:     "unsupported keyword in yaml",
+			filename: "kep.schema.yaml",
+			content:  "properties:\n  kep-number:\n    multipleOf: 2\n",
+			expected: `unsupported keyword "multipleOf"`,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			filename := tc.filename
+			if filename == "" {
+				filename = "kep.schema.json"
+			}
+			path := writeSchema(t, filename, tc.content)
+			defer os.RemoveAll(filepath.Dir(path))
+			_, err := LoadSchema(path)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.expected) {
+				t.Fatalf("expected %q in the error but got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestSchemaRecursion(t *testing.T) {
+	// A schema may refer to itself for values nested inside the one it checks.
+	path := writeSchema(t, "kep.schema.json", `{
+  "$ref": "#/$defs/tree",
+  "$defs": {"tree": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/$defs/tree"}}}}}
+}`)
+	defer os.RemoveAll(filepath.Dir(path))
+	schema, err := LoadSchema(path)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	parsed := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte("children:\n  - children: []\n  - children: [1]\n"), parsed); err != nil {
+		t.Fatal(err)
+	}
+	errs := schema.Validate(parsed, func(string) (int, int) { return 1, 1 })
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "/children/1/children/0: must be object") {
+		t.Fatalf("expected the nested value to be checked but got %v", errs)
+	}
+}
+
+func TestConfigSchema(t *testing.T) {
+	// The schema generated from the default rules accepts the same metadata.
+	data, err := json.Marshal(DefaultConfig().Schema(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "kepval-schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "kep.schema.yaml"), []byte("$schema: "+SchemaDialect+"\nallOf:\n  - $ref: '#/$defs/kep'\n$defs:\n  kep: "+string(data)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ConfigFilename), []byte("schema: kep.schema.yaml\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := LoadConfig(filepath.Join(dir, ConfigFilename), "")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	p := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte("title: t\nauthors: []\nreplaces:\n"), p); err != nil {
+		t.Fatal(err)
+	}
+	errs := c.ValidateKeys(p, func(string) (int, int) { return 1, 1 })
+	if len(errs) != 1 || errs[0].Err.Error() != "/authors: must have at least one item" {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
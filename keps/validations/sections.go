@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type MissingSection struct {
+	title string
+}
+
+func (m *MissingSection) Error() string {
+	return fmt.Sprintf("missing required section %q", m.title)
+}
+
+type PlaceholderSection struct {
+	title string
+}
+
+func (p *PlaceholderSection) Error() string {
+	return fmt.Sprintf("section %q has not been filled in", p.title)
+}
+
+// DefaultStatusSections is the key in Config.Sections for the statuses that
+// aren't listed.
+const DefaultStatusSections = "default"
+
+// defaultSections are the sections the kubernetes/enhancements template
+// requires for each status.
+func defaultSections() map[string][]string {
+	all := []string{"Summary", "Motivation", "Proposal", "Design Details", "Test Plan", "Graduation Criteria"}
+	closed := []string{"Summary"}
+	return map[string][]string{
+		"implementable":       all,
+		"implemented":         all,
+		"deferred":            closed,
+		"rejected":            closed,
+		"withdrawn":           closed,
+		"replaced":            closed,
+		DefaultStatusSections: {"Summary", "Motivation", "Proposal"},
+	}
+}
+
+// RequiredSections returns the body sections a KEP with the given status must
+// have. The kubernetes/enhancements sections are used when the configuration
+// doesn't list any.
+func (c *Config) RequiredSections(status string) []string {
+	sections := defaultSections()
+	if c != nil && c.Sections != nil {
+		sections = c.Sections
+	}
+	if required, ok := sections[strings.ToLower(status)]; ok {
+		return required
+	}
+	return sections[DefaultStatusSections]
+}
+
+// SectionLookup returns the line number and content of the section with the
+// given title, or false if the KEP has no such section.
+type SectionLookup func(title string) (int, string, bool)
+
+// ValidateSections checks that a KEP with the given status has every required
+// section and that each one has been filled in. Missing sections are reported
+// on bodyLine, the first line of the KEP body.
+func (c *Config) ValidateSections(status string, bodyLine int, lookup SectionLookup) ErrorList {
+	var errs ErrorList
+	for _, title := range c.RequiredSections(status) {
+		line, content, ok := lookup(title)
+		if !ok {
+			errs = append(errs, &Error{Line: bodyLine, Err: &MissingSection{title}})
+			continue
+		}
+		if err := ValidateSectionContent(title, content); err != nil {
+			errs = append(errs, &Error{Line: line, Err: err})
+		}
+	}
+	return errs
+}
+
+var (
+	commentRe     = regexp.MustCompile(`(?s)<!--.*?-->`)
+	placeholderRe = regexp.MustCompile(`(?i)^(tbd|todo|tba|to be determined|to be done|fill me in|\.\.\.)[.!]*$|^note:.*not required until`)
+)
+
+// ValidateSectionContent returns an error if a section only contains template
+// guidance, which lives in HTML comments, headings or placeholder text such as
+// "TBD".
+func ValidateSectionContent(title, content string) error {
+	content = commentRe.ReplaceAllString(content, "")
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.Trim(strings.TrimSpace(line), "*_[]()`")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if placeholderRe.MatchString(strings.TrimSpace(line)) {
+			continue
+		}
+		return nil
+	}
+	return &PlaceholderSection{title}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateSectionContent(t *testing.T) {
+	testcases := []struct {
+		name        string
+		content     string
+		placeholder bool
+	}{
+		{"filled in", "\nWe will add e2e tests.\n", false},
+		{"empty", "\n\n", true},
+		{"only comments", "\n<!--\nDescribe the test plan.\n-->\n", true},
+		{"tbd", "\nTBD\n", true},
+		{"emphasised todo", "\n*TODO.*\n", true},
+		{"template note", "\n**Note:** *Section not required until targeted at a release.*\n", true},
+		{"only empty subsections", "\n### Unit tests\n\nTBD\n", true},
+		{"filled in subsection", "\n### Unit tests\n\n- pkg/foo: 80%\n", false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateSectionContent("Test Plan", tc.content)
+			if tc.placeholder && err == nil {
+				t.Fatal("expected a placeholder error")
+			}
+			if !tc.placeholder && err != nil {
+				t.Fatalf("did not expect an error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateSections(t *testing.T) {
+	sections := map[string]string{
+		"Summary":             "A summary.",
+		"Motivation":          "Reasons.",
+		"Proposal":            "A proposal.",
+		"Design Details":      "Details.",
+		"Graduation Criteria": "TBD",
+	}
+	lookup := func(title string) (int, string, bool) {
+		content, ok := sections[title]
+		return 10, content, ok
+	}
+
+	config := DefaultConfig()
+	if errs := config.ValidateSections("provisional", 5, lookup); len(errs) != 0 {
+		t.Fatalf("did not expect errors for a provisional KEP: %v", errs)
+	}
+
+	errs := config.ValidateSections("implementable", 5, lookup)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors but got %v", errs)
+	}
+	if _, ok := errs[0].Err.(*MissingSection); !ok || errs[0].Line != 5 {
+		t.Fatalf("expected a missing Test Plan on line 5 but got %v", errs[0])
+	}
+	if _, ok := errs[1].Err.(*PlaceholderSection); !ok || errs[1].Line != 10 {
+		t.Fatalf("expected a placeholder Graduation Criteria on line 10 but got %v", errs[1])
+	}
+}
+
+func TestRequiredSections(t *testing.T) {
+	testcases := []struct {
+		name     string
+		sections map[string][]string
+		status   string
+		expected []string
+	}{
+		{
+			name:     "default closed",
+			status:   "withdrawn",
+			expected: []string{"Summary"},
+		},
+		{
+			name:     "default unlisted",
+			status:   "provisional",
+			expected: []string{"Summary", "Motivation", "Proposal"},
+		},
+		{
+			name:     "configured",
+			sections: map[string][]string{"Implementable": {"Summary", "Risks"}},
+			status:   "implementable",
+			expected: []string{"Summary", "Risks"},
+		},
+		{
+			name:     "configured default",
+			sections: map[string][]string{"default": {"Summary"}},
+			status:   "provisional",
+			expected: []string{"Summary"},
+		},
+		{
+			name:     "configured unlisted",
+			sections: map[string][]string{"implemented": {"Summary"}},
+			status:   "provisional",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Sections = tc.sections
+			if err := config.compile(); err != nil {
+				t.Fatalf("did not expect an error: %v", err)
+			}
+			if actual := config.RequiredSections(tc.status); !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("expected %v but got %v", tc.expected, actual)
+			}
+		})
+	}
+}
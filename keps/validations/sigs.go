@@ -0,0 +1,202 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// SIGRegistry knows the SIGs, working groups and committees that can own or
+// participate in a KEP.
+type SIGRegistry struct {
+	// names are the canonical names, such as sig-network.
+	names []string
+	// aliases maps normalised names and display names to canonical names.
+	aliases map[string]string
+}
+
+type UnknownSIG struct {
+	key        string
+	value      string
+	suggestion string
+}
+
+func (u *UnknownSIG) Error() string {
+	if u.suggestion != "" {
+		return fmt.Sprintf("%q has unknown SIG %q, did you mean %q?", u.key, u.value, u.suggestion)
+	}
+	return fmt.Sprintf("%q has unknown SIG %q", u.key, u.value)
+}
+
+// sigsFile is the part of the kubernetes/community sigs.yaml format that
+// names groups.
+type sigsFile struct {
+	SIGs          []sigsGroup `yaml:"sigs"`
+	WorkingGroups []sigsGroup `yaml:"workinggroups"`
+	UserGroups    []sigsGroup `yaml:"usergroups"`
+	Committees    []sigsGroup `yaml:"committees"`
+}
+
+type sigsGroup struct {
+	Dir  string `yaml:"dir"`
+	Name string `yaml:"name"`
+}
+
+// NewSIGRegistry returns a registry of the given canonical names.
+func NewSIGRegistry(names ...string) *SIGRegistry {
+	r := &SIGRegistry{aliases: map[string]string{}}
+	for _, name := range names {
+		r.add(name, "")
+	}
+	return r
+}
+
+// LoadSIGRegistry reads a sigs.yaml file in the kubernetes/community format.
+// Groups are known by their directory, such as sig-network, and their name.
+func LoadSIGRegistry(path string) (*SIGRegistry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f := &sigsFile{}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, errors.Wrapf(err, "error reading %v", path)
+	}
+	r := NewSIGRegistry()
+	for _, groups := range [][]sigsGroup{f.SIGs, f.WorkingGroups, f.UserGroups, f.Committees} {
+		for _, g := range groups {
+			if g.Dir != "" {
+				r.add(g.Dir, g.Name)
+			}
+		}
+	}
+	if len(r.names) == 0 {
+		return nil, errors.Errorf("error reading %v: no sigs found", path)
+	}
+	return r, nil
+}
+
+func (r *SIGRegistry) add(name, displayName string) {
+	canonical := normaliseSIG(name)
+	if _, ok := r.aliases[canonical]; !ok {
+		r.names = append(r.names, canonical)
+		sort.Strings(r.names)
+	}
+	r.aliases[canonical] = canonical
+	if displayName != "" {
+		r.aliases[normaliseSIG(displayName)] = canonical
+	}
+	// sig-network may be written as network.
+	if i := strings.Index(canonical, "-"); i > 0 {
+		if _, ok := r.aliases[canonical[i+1:]]; !ok {
+			r.aliases[canonical[i+1:]] = canonical
+		}
+	}
+}
+
+// Names returns the canonical names of every group.
+func (r *SIGRegistry) Names() []string {
+	return append([]string(nil), r.names...)
+}
+
+// Canonical returns the canonical name of a SIG written as its directory, its
+// name or without its sig- prefix, in any case.
+func (r *SIGRegistry) Canonical(name string) (string, bool) {
+	canonical, ok := r.aliases[normaliseSIG(name)]
+	return canonical, ok
+}
+
+// Suggest returns the known SIG closest to an unknown name, if any is close.
+func (r *SIGRegistry) Suggest(name string) string {
+	n := normaliseSIG(name)
+	best, bestDistance := "", len(n)/3+2
+	for alias, canonical := range r.aliases {
+		if d := editDistance(n, alias); d < bestDistance || (d == bestDistance && canonical < best) {
+			best, bestDistance = canonical, d
+		}
+	}
+	return best
+}
+
+// Validate returns an error for every unknown SIG in the owning-sig and
+// participating-sigs keys.
+func (r *SIGRegistry) Validate(parsed map[interface{}]interface{}, position KeyPosition) ErrorList {
+	var errs ErrorList
+	check := func(key string, value interface{}) {
+		name, ok := value.(string)
+		if !ok || name == "" {
+			return
+		}
+		if _, ok := r.Canonical(name); ok {
+			return
+		}
+		line, column := position(key)
+		errs = append(errs, &Error{Line: line, Column: column, Err: &UnknownSIG{key, name, r.Suggest(name)}})
+	}
+	check("owning-sig", parsed["owning-sig"])
+	if sigs, ok := parsed["participating-sigs"].([]interface{}); ok {
+		for _, sig := range sigs {
+			check("participating-sigs", sig)
+		}
+	}
+	return errs
+}
+
+// normaliseSIG lower cases a SIG name and joins its words with dashes, so
+// "SIG Network" becomes sig-network.
+func normaliseSIG(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '\t'
+	}), "-")
+}
+
+// editDistance is the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+const testSIGs = `sigs:
+  - dir: sig-network
+    name: Network
+    mission_statement: >
+      Networking.
+  - dir: sig-api-machinery
+    name: API Machinery
+workinggroups:
+  - dir: wg-policy
+    name: Policy
+committees:
+  - dir: committee-steering
+    name: Steering
+`
+
+func TestLoadSIGRegistry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kepval-sigs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sigs.yaml")
+	if err := ioutil.WriteFile(path, []byte(testSIGs), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := LoadSIGRegistry(path)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	testcases := []struct {
+		name      string
+		canonical string
+	}{
+		{"sig-network", "sig-network"},
+		{"SIG Network", "sig-network"},
+		{"network", "sig-network"},
+		{"api-machinery", "sig-api-machinery"},
+		{"API Machinery", "sig-api-machinery"},
+		{"wg-policy", "wg-policy"},
+		{"steering", "committee-steering"},
+		{"sig-netwrok", ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			canonical, ok := r.Canonical(tc.name)
+			if canonical != tc.canonical || ok != (tc.canonical != "") {
+				t.Fatalf("expected %q but got %q", tc.canonical, canonical)
+			}
+		})
+	}
+}
+
+func TestSIGRegistryValidate(t *testing.T) {
+	r := NewSIGRegistry("sig-network", "sig-node", "sig-storage")
+	p := map[interface{}]interface{}{}
+	doc := "owning-sig: sig-netwrok\nparticipating-sigs:\n  - sig-node\n  - sig-quantum\n"
+	if err := yaml.Unmarshal([]byte(doc), p); err != nil {
+		t.Fatal(err)
+	}
+	lines := map[string]int{"owning-sig": 2, "participating-sigs": 3}
+	errs := r.Validate(p, func(key string) (int, int) { return lines[key], 1 })
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors but got %v", errs)
+	}
+	expected := []string{
+		`"owning-sig" has unknown SIG "sig-netwrok", did you mean "sig-network"?`,
+		`"participating-sigs" has unknown SIG "sig-quantum"`,
+	}
+	for i, e := range expected {
+		if errs[i].Err.Error() != e || errs[i].Code() != "unknown-sig" {
+			t.Fatalf("expected %q but got %q", e, errs[i].Err.Error())
+		}
+	}
+	if errs[0].Line != 2 || errs[1].Line != 3 {
+		t.Fatalf("unexpected lines %d and %d", errs[0].Line, errs[1].Line)
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// suppressionPrefix starts a suppression directive inside a YAML comment.
+const suppressionPrefix = "kepval:"
+
+var suppressionRe = regexp.MustCompile(`^kepval:ignore=([a-z0-9-]+(?:,[a-z0-9-]+)*)(?:\s+reason="([^"]*)")?\s*$`)
+
+// Suppression turns off rules for a single KEP. It is written as a comment in
+// the front matter:
+//
+//	# kepval:ignore=stale-last-updated,missing-editor reason="archived"
+type Suppression struct {
+	Line   int
+	Codes  []string
+	Reason string
+
+	used map[string]bool
+}
+
+type InvalidSuppression struct {
+	directive string
+}
+
+func (i *InvalidSuppression) Error() string {
+	return fmt.Sprintf("%q is not a suppression, use kepval:ignore=<code>[,<code>] reason=\"...\"", i.directive)
+}
+
+type UnusedSuppression struct {
+	code string
+}
+
+func (u *UnusedSuppression) Error() string {
+	return fmt.Sprintf("nothing to suppress for %q", u.code)
+}
+
+type SuppressionWithoutReason struct {
+	codes []string
+}
+
+func (s *SuppressionWithoutReason) Error() string {
+	return fmt.Sprintf("suppression of %s needs a reason=\"...\"", strings.Join(s.codes, ", "))
+}
+
+// ParseSuppressions finds the suppression directives in the comments of KEP
+// metadata starting at firstLine. Malformed directives and directives
+// without a reason are returned as warnings.
+func ParseSuppressions(metadata []byte, firstLine int) ([]*Suppression, ErrorList) {
+	var suppressions []*Suppression
+	var errs ErrorList
+	for i, line := range strings.Split(string(metadata), "\n") {
+		comment := yamlComment(line)
+		if comment < 0 {
+			continue
+		}
+		directive := strings.TrimSpace(line[comment+1:])
+		if !strings.HasPrefix(directive, suppressionPrefix) {
+			continue
+		}
+		column := comment + 1
+		match := suppressionRe.FindStringSubmatch(directive)
+		if match == nil {
+			errs = append(errs, &Error{Line: firstLine + i, Column: column, Severity: SeverityWarning, Err: &InvalidSuppression{directive}})
+			continue
+		}
+		s := &Suppression{
+			Line:   firstLine + i,
+			Codes:  strings.Split(match[1], ","),
+			Reason: strings.TrimSpace(match[2]),
+			used:   map[string]bool{},
+		}
+		if s.Reason == "" {
+			errs = append(errs, &Error{Line: s.Line, Column: column, Severity: SeverityWarning, Err: &SuppressionWithoutReason{s.Codes}})
+		}
+		suppressions = append(suppressions, s)
+	}
+	return suppressions, errs
+}
+
+// yamlComment returns the index of the # starting a comment on a line of
+// YAML or -1. A # only starts a comment at the start of the line or after
+// whitespace, outside of quotes.
+func yamlComment(line string) int {
+	var quote rune
+	for i, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t[,", rune(line[i-1])) {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Suppress drops the errors whose rule is suppressed and remembers which
+// suppressions were used. Problems with suppressions themselves can't be
+// suppressed.
+func Suppress(errs ErrorList, suppressions []*Suppression) ErrorList {
+	if len(suppressions) == 0 {
+		return errs
+	}
+	out := make(ErrorList, 0, len(errs))
+	for _, err := range errs {
+		if !suppressed(err, suppressions) {
+			out = append(out, err)
+		}
+	}
+	return out
+}
+
+func suppressed(err *Error, suppressions []*Suppression) bool {
+	switch err.Err.(type) {
+	case *InvalidSuppression, *UnusedSuppression, *SuppressionWithoutReason:
+		return false
+	}
+	code := err.Code()
+	for _, s := range suppressions {
+		for _, c := range s.Codes {
+			if c == code {
+				s.used[c] = true
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UnusedSuppressions returns a warning for every suppressed rule that didn't
+// suppress anything. Call it once every check has run.
+func UnusedSuppressions(suppressions []*Suppression) ErrorList {
+	var errs ErrorList
+	for _, s := range suppressions {
+		for _, c := range s.Codes {
+			if !s.used[c] {
+				errs = append(errs, &Error{Line: s.Line, Severity: SeverityWarning, Err: &UnusedSuppression{c}})
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSuppressions(t *testing.T) {
+	metadata := []byte(`title: test
+# kepval:ignore=stale-last-updated reason="archived"
+editor: TBD # kepval:ignore=placeholder-value,missing-editor
+quoted: "# kepval:ignore=not-a-comment"
+# kepval:ignroe=typo
+# an ordinary comment
+`)
+	suppressions, errs := ParseSuppressions(metadata, 2)
+	if len(suppressions) != 2 {
+		t.Fatalf("expected 2 suppressions but got %d", len(suppressions))
+	}
+	if suppressions[0].Line != 3 || suppressions[0].Reason != "archived" || !reflect.DeepEqual(suppressions[0].Codes, []string{"stale-last-updated"}) {
+		t.Fatalf("unexpected suppression %+v", suppressions[0])
+	}
+	if !reflect.DeepEqual(suppressions[1].Codes, []string{"placeholder-value", "missing-editor"}) {
+		t.Fatalf("unexpected codes %v", suppressions[1].Codes)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors but got %v", errs)
+	}
+	if errs[0].Code() != "suppression-without-reason" || errs[0].Line != 4 {
+		t.Fatalf("unexpected error %v", errs[0])
+	}
+	if errs[1].Code() != "invalid-suppression" || errs[1].Line != 6 {
+		t.Fatalf("unexpected error %v", errs[1])
+	}
+}
+
+func TestSuppress(t *testing.T) {
+	suppressions, _ := ParseSuppressions([]byte("# kepval:ignore=missing-editor,stale-toc reason=\"legacy\"\n"), 2)
+	in := ErrorList{
+		{Line: 2, Err: &MissingEditor{}},
+		{Line: 3, Err: &MissingKey{"title"}},
+	}
+	errs := Suppress(in, suppressions)
+	if len(errs) != 1 || errs[0].Code() != "missing-key" {
+		t.Fatalf("expected only missing-key to remain but got %v", errs)
+	}
+	if in[0].Code() != "missing-editor" {
+		t.Fatalf("expected the errors passed in to be left alone but got %v", in)
+	}
+	unused := UnusedSuppressions(suppressions)
+	if len(unused) != 1 || unused[0].Code() != "unused-suppression" || unused[0].Line != 2 {
+		t.Fatalf("expected stale-toc to be unused but got %v", unused)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"fmt"
+	"strings"
+)
+
+type StaleTableOfContents struct {
+	expected string
+	found    string
+}
+
+func (s *StaleTableOfContents) Error() string {
+	if s.found == "" {
+		return fmt.Sprintf("table of contents is out of date: missing %q", s.expected)
+	}
+	if s.expected == "" {
+		return fmt.Sprintf("table of contents is out of date: unexpected %q", s.found)
+	}
+	return fmt.Sprintf("table of contents is out of date: expected %q but found %q", s.expected, s.found)
+}
+
+// ValidateTableOfContents compares an existing table of contents, starting on
+// firstLine, with the one generated from the headings. Blank lines and
+// trailing whitespace are ignored. The error points at the first entry that
+// differs.
+func ValidateTableOfContents(firstLine int, existing, expected string) *Error {
+	type entry struct {
+		line int
+		text string
+	}
+	entries := func(toc string, first int) []entry {
+		var out []entry
+		for i, line := range strings.Split(toc, "\n") {
+			line = strings.TrimRight(line, " \t\r")
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			out = append(out, entry{first + i, line})
+		}
+		return out
+	}
+	found := entries(existing, firstLine)
+	want := entries(expected, firstLine)
+
+	for i := 0; i < len(found) || i < len(want); i++ {
+		switch {
+		case i >= len(found):
+			line := firstLine
+			if len(found) > 0 {
+				line = found[len(found)-1].line + 1
+			}
+			return &Error{Line: line, Err: &StaleTableOfContents{expected: want[i].text}}
+		case i >= len(want):
+			return &Error{Line: found[i].line, Err: &StaleTableOfContents{found: found[i].text}}
+		case found[i].text != want[i].text:
+			return &Error{Line: found[i].line, Err: &StaleTableOfContents{expected: want[i].text, found: found[i].text}}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StaleAfter is how long an active KEP can go without being updated.
+const StaleAfter = 365 * 24 * time.Hour
+
+// activeStatuses are the statuses of KEPs that are still being worked on.
+var activeStatuses = map[string]bool{
+	"provisional":   true,
+	"implementable": true,
+}
+
+type MissingEditor struct{}
+
+func (m *MissingEditor) Error() string {
+	return `"editor" is not set`
+}
+
+type StaleLastUpdated struct {
+	lastUpdated string
+}
+
+func (s *StaleLastUpdated) Error() string {
+	return fmt.Sprintf("\"last-updated\" is %q, more than a year ago", s.lastUpdated)
+}
+
+type PlaceholderValue struct {
+	key string
+}
+
+func (p *PlaceholderValue) Error() string {
+	return fmt.Sprintf("%q still has the placeholder value TBD", p.key)
+}
+
+// ValidateWarnings returns warnings about metadata that is valid but probably
+// not what the authors want: no editor, an active KEP that hasn't been updated
+// for a long time, or TBD values left behind by kepfix.
+func ValidateWarnings(parsed map[interface{}]interface{}, position KeyPosition, now time.Time) ErrorList {
+	var errs ErrorList
+	warn := func(key string, err error) {
+		line, column := position(key)
+		errs = append(errs, &Error{Line: line, Column: column, Severity: SeverityWarning, Err: err})
+	}
+
+	if editor, _ := parsed["editor"].(string); editor == "" {
+		warn("editor", &MissingEditor{})
+	}
+
+	status, _ := parsed["status"].(string)
+	lastUpdated, _ := parsed["last-updated"].(string)
+	if date, err := time.Parse("2006-01-02", lastUpdated); err == nil && activeStatuses[strings.ToLower(status)] {
+		if now.Sub(date) > StaleAfter {
+			warn("last-updated", &StaleLastUpdated{lastUpdated})
+		}
+	}
+
+	for key, value := range parsed {
+		k, ok := key.(string)
+		if !ok {
+			continue
+		}
+		if isPlaceholder(value) {
+			warn(k, &PlaceholderValue{k})
+		}
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Error() < errs[j].Error()
+	})
+	return errs
+}
+
+func isPlaceholder(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.EqualFold(strings.TrimSpace(v), "TBD")
+	case []interface{}:
+		for _, item := range v {
+			if isPlaceholder(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validations
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestValidateWarnings(t *testing.T) {
+	now := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	testcases := []struct {
+		name  string
+		doc   string
+		codes []string
+	}{
+		{
+			name: "no warnings",
+			doc:  "editor: someone\nstatus: provisional\nlast-updated: 2019-05-01\n",
+		},
+		{
+			name:  "missing editor",
+			doc:   "status: provisional\nlast-updated: 2019-05-01\n",
+			codes: []string{"missing-editor"},
+		},
+		{
+			name:  "stale active KEP",
+			doc:   "editor: someone\nstatus: implementable\nlast-updated: 2018-01-01\n",
+			codes: []string{"stale-last-updated"},
+		},
+		{
+			name: "old implemented KEP",
+			doc:  "editor: someone\nstatus: implemented\nlast-updated: 2018-01-01\n",
+		},
+		{
+			name:  "TBD values",
+			doc:   "editor: TBD\nstatus: provisional\nlast-updated: 2019-05-01\nreviewers: [a, TBD]\n",
+			codes: []string{"placeholder-value", "placeholder-value"},
+		},
+	}
+	lines := map[string]int{"editor": 1, "status": 2, "last-updated": 3, "reviewers": 4}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := map[interface{}]interface{}{}
+			if err := yaml.Unmarshal([]byte(tc.doc), p); err != nil {
+				t.Fatal(err)
+			}
+			errs := ValidateWarnings(p, func(key string) (int, int) { return lines[key], 1 }, now)
+			if len(errs) != len(tc.codes) {
+				t.Fatalf("expected %v but got %v", tc.codes, errs)
+			}
+			for i, code := range tc.codes {
+				if errs[i].Code() != code || errs[i].Severity != SeverityWarning {
+					t.Fatalf("expected a %q warning but got %v %q", code, errs[i].Severity, errs[i].Code())
+				}
+			}
+		})
+	}
+}
@@ -18,7 +18,11 @@ package validations
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+
+	"gopkg.in/yaml.v2"
 )
 
 type KeyMustBeString struct {
@@ -62,59 +66,45 @@ type MustHaveAtLeastOneValue struct {
 func (m *MustHaveAtLeastOneValue) Error() string {
 	return fmt.Sprintf("%q must have at least one value", m.key)
 }
+
+var yamlLineRe = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// YAMLErrors turns an error from unmarshaling KEP metadata into positioned
+// errors. firstLine is the line of the file the metadata starts on.
+func YAMLErrors(err error, firstLine int) ErrorList {
+	messages := []string{err.Error()}
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		messages = typeErr.Errors
+	}
+	var errs ErrorList
+	for _, msg := range messages {
+		line := firstLine
+		if m := yamlLineRe.FindStringSubmatch(msg); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			line, msg = firstLine+n-1, m[2]
+		}
+		errs = append(errs, &Error{Line: line, Err: &InvalidYAML{strings.TrimPrefix(msg, "yaml: ")}})
+	}
+	return errs
+}
+
+// ValidateStructure returns the first structural error in the parsed KEP
+// metadata using the default rules.
 func ValidateStructure(parsed map[interface{}]interface{}) error {
+	c := DefaultConfig()
 	for key, value := range parsed {
-		// First off the key has to be a string. fact.
-		k, ok := key.(string)
-		if !ok {
-			return &KeyMustBeString{k}
-		}
-		empty := value == nil
-
-		// figure out the types
-		switch strings.ToLower(k) {
-		// optional strings
-		case "editor":
-			if empty {
-				continue
-			}
-			fallthrough
-		case "title", "owning-sig", "status", "creation-date", "last-updated":
-			switch v := value.(type) {
-			case []interface{}:
-				return &ValueMustBeString{k, v}
-			}
-			v, ok := value.(string)
-			if !ok && v == "" {
-				return &MustHaveOneValue{k}
-			}
-			if !ok {
-				return &ValueMustBeString{k, v}
-			}
-		// These are optional lists, so skip if there is no value
-		case "participating-sigs", "replaces", "superseded-by", "see-also":
-			if empty {
-				continue
-			}
-			switch v := value.(type) {
-			case []interface{}:
-				if len(v) == 0 {
-					continue
-				}
-			case interface{}:
-				continue
-			}
-			fallthrough
-		case "authors", "reviewers", "approvers":
-			switch v := value.(type) {
-			case []interface{}:
-				if len(v) == 0 {
-					return &MustHaveAtLeastOneValue{k}
-				}
-			case interface{}:
-				return &ValueMustBeListOfStrings{k, v}
-			}
+		if _, err := c.validateKey(key, value); err != nil {
+			return err
 		}
 	}
 	return nil
 }
+
+// KeyPosition returns the line and column of a top level key in the KEP file.
+type KeyPosition func(key string) (int, int)
+
+// ValidateKeys returns every structural error in the parsed KEP metadata using
+// the default rules.
+func ValidateKeys(parsed map[interface{}]interface{}, position KeyPosition) ErrorList {
+	return DefaultConfig().ValidateKeys(parsed, position)
+}
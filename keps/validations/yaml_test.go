@@ -116,3 +116,42 @@ func TestUnmarshal(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestValidateKeys(t *testing.T) {
+	p := map[interface{}]interface{}{}
+	doc := []byte("title:\n  - a list\nauthors: someone\nreviewers: []\n")
+	if err := yaml.Unmarshal(doc, p); err != nil {
+		t.Fatal(err)
+	}
+	lines := map[string]int{"title": 2, "authors": 4, "reviewers": 5}
+	errs := ValidateKeys(p, func(key string) (int, int) {
+		return lines[key], 1
+	})
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors but got %v", errs)
+	}
+	codes := []string{"value-must-be-string", "value-must-be-list-of-strings", "must-have-at-least-one-value"}
+	for i, code := range codes {
+		if errs[i].Code() != code {
+			t.Fatalf("expected error %d to be %q but got %q", i, code, errs[i].Code())
+		}
+	}
+	if errs[0].Line != 2 || errs[2].Line != 5 {
+		t.Fatalf("errors are not ordered by line: %v", errs)
+	}
+}
+
+func TestYAMLErrors(t *testing.T) {
+	p := map[interface{}]interface{}{}
+	err := yaml.Unmarshal([]byte("title: test\nauthors: [a\n"), p)
+	if err == nil {
+		t.Fatal("expected a syntax error")
+	}
+	errs := YAMLErrors(err, 10)
+	if len(errs) != 1 {
+		t.Fatalf("expected a single error but got %v", errs)
+	}
+	if errs[0].Line < 10 || errs[0].Code() != "invalid-yaml" {
+		t.Fatalf("unexpected error %v", errs[0])
+	}
+}